@@ -0,0 +1,317 @@
+package md2sql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// maxChains is the number of dependency chains in the analysis report.
+const maxChains = 5
+
+// Analysis is a report of the shape of the relationship graph.
+type Analysis struct {
+	TableCount    int          `json:"tableCount"`
+	RelationCount int          `json:"relationCount"`
+	Orphans       []string     `json:"orphans"`
+	Cycles        [][]string   `json:"cycles"`
+	NotNullCycles [][]string   `json:"notNullCycles"`
+	Tables        []TableStats `json:"tables"`
+	LongestChains [][]string   `json:"longestChains"`
+}
+
+// TableStats has the numbers of relations of the table.
+// FanIn is the number of references from other tables and FanOut is the number of references to other tables.
+type TableStats struct {
+	Name   string `json:"name"`
+	FanIn  int    `json:"fanIn"`
+	FanOut int    `json:"fanOut"`
+}
+
+type graph struct {
+	nodes []string
+	index map[string]int
+	edges [][]int
+}
+
+func newGraph(tables []*Table) *graph {
+	g := &graph{
+		index: make(map[string]int),
+		edges: make([][]int, len(tables)),
+	}
+	for i, t := range tables {
+		g.nodes = append(g.nodes, t.Name)
+		g.index[t.Name] = i
+	}
+	return g
+}
+
+func (g *graph) addEdge(from, to string) {
+	f, ok1 := g.index[from]
+	t, ok2 := g.index[to]
+	if ok1 && ok2 {
+		g.edges[f] = append(g.edges[f], t)
+	}
+}
+
+// components returns strongly connected components by Tarjan's algorithm.
+// Each component and the list of components are sorted in the node order.
+func (g *graph) components() [][]int {
+	index := 0
+	indices := make([]int, len(g.nodes))
+	lowlinks := make([]int, len(g.nodes))
+	onStack := make([]bool, len(g.nodes))
+	for i := range indices {
+		indices[i] = -1
+	}
+	var stack []int
+	var result [][]int
+
+	var connect func(v int)
+	connect = func(v int) {
+		indices[v] = index
+		lowlinks[v] = index
+		index++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range g.edges[v] {
+			if indices[w] == -1 {
+				connect(w)
+				if lowlinks[w] < lowlinks[v] {
+					lowlinks[v] = lowlinks[w]
+				}
+			} else if onStack[w] && indices[w] < lowlinks[v] {
+				lowlinks[v] = indices[w]
+			}
+		}
+		if lowlinks[v] == indices[v] {
+			var component []int
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			sort.Ints(component)
+			result = append(result, component)
+		}
+	}
+	for v := range g.nodes {
+		if indices[v] == -1 {
+			connect(v)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0]
+	})
+	return result
+}
+
+// cycles returns components that have cycles including self references.
+func (g *graph) cycles() [][]string {
+	result := [][]string{}
+	for _, c := range g.components() {
+		if len(c) == 1 && !contains(g.edges[c[0]], c[0]) {
+			continue
+		}
+		result = append(result, g.names(c))
+	}
+	return result
+}
+
+func (g *graph) names(nodes []int) []string {
+	result := make([]string, len(nodes))
+	for i, n := range nodes {
+		result[i] = g.nodes[n]
+	}
+	return result
+}
+
+// longestChains returns the longest dependency chains from tables that no table in other components depends on.
+// Cycles are condensed into their components: a chain enters a component, takes the shortest path
+// to the table that refers the next component and leaves it. Chains that stay in a cycle are reported as cycles.
+func (g *graph) longestChains(limit int) [][]string {
+	components := g.components()
+	component := make([]int, len(g.nodes))
+	for i, c := range components {
+		for _, n := range c {
+			component[n] = i
+		}
+	}
+	referred := make([]bool, len(components))
+	for v, edges := range g.edges {
+		for _, w := range edges {
+			if component[v] != component[w] {
+				referred[component[w]] = true
+			}
+		}
+	}
+	// longest chain from each node in the DAG of components
+	chains := make([][]int, len(g.nodes))
+	var visit func(v int)
+	visit = func(v int) {
+		if chains[v] != nil {
+			return
+		}
+		chains[v] = []int{v}
+		for _, x := range components[component[v]] {
+			for _, w := range g.edges[x] {
+				if component[x] == component[w] {
+					continue
+				}
+				visit(w)
+				path := g.path(v, x, component)
+				if len(path)+len(chains[w]) > len(chains[v]) {
+					chains[v] = append(path, chains[w]...)
+				}
+			}
+		}
+	}
+	var roots []int
+	for i, c := range components {
+		if referred[i] {
+			continue
+		}
+		root := c[0]
+		for _, v := range c {
+			visit(v)
+			if len(chains[v]) > len(chains[root]) {
+				root = v
+			}
+		}
+		if len(chains[root]) > 1 {
+			roots = append(roots, root)
+		}
+	}
+	sort.SliceStable(roots, func(i, j int) bool {
+		return len(chains[roots[i]]) > len(chains[roots[j]])
+	})
+	if len(roots) > limit {
+		roots = roots[:limit]
+	}
+	result := [][]string{}
+	for _, v := range roots {
+		result = append(result, g.names(chains[v]))
+	}
+	return result
+}
+
+// path returns the shortest path from v to w in the component of v.
+func (g *graph) path(v, w int, component []int) []int {
+	prev := make(map[int]int)
+	queue := []int{v}
+	prev[v] = -1
+	for len(queue) > 0 && queue[0] != w {
+		n := queue[0]
+		queue = queue[1:]
+		for _, m := range g.edges[n] {
+			if _, ok := prev[m]; !ok && component[m] == component[v] {
+				prev[m] = n
+				queue = append(queue, m)
+			}
+		}
+	}
+	var result []int
+	for n := w; n != -1; n = prev[n] {
+		result = append([]int{n}, result...)
+	}
+	return result
+}
+
+// Analyze reports orphan tables, foreign key cycles, fan-in/fan-out and the longest dependency chains.
+//
+// References to associative entities ([] suffix) are counted as fan-in/fan-out,
+// but they are not dependencies because they are stored in separated tables.
+func Analyze(tables []*Table) *Analysis {
+	relations := buildRelations(tables)
+	all := newGraph(tables)
+	notNull := newGraph(tables)
+	stats := make(map[string]*TableStats)
+	for _, t := range tables {
+		stats[t.Name] = &TableStats{Name: t.Name}
+	}
+	for _, r := range relations {
+		if s, ok := stats[r.FromTable]; ok {
+			s.FanOut++
+		}
+		if s, ok := stats[r.ToTable]; ok {
+			s.FanIn++
+		}
+		if r.ToCardinality == ZeroOrMore {
+			continue
+		}
+		all.addEdge(r.FromTable, r.ToTable)
+		if r.ToCardinality == ExactlyOne {
+			notNull.addEdge(r.FromTable, r.ToTable)
+		}
+	}
+
+	result := &Analysis{
+		TableCount:    len(tables),
+		RelationCount: len(relations),
+		Orphans:       []string{},
+		Cycles:        all.cycles(),
+		NotNullCycles: notNull.cycles(),
+		LongestChains: all.longestChains(maxChains),
+	}
+	for _, t := range tables {
+		s := stats[t.Name]
+		if s.FanIn == 0 && s.FanOut == 0 {
+			result.Orphans = append(result.Orphans, t.Name)
+		}
+		result.Tables = append(result.Tables, *s)
+	}
+	return result
+}
+
+// DumpAnalysis writes the analysis report as text.
+func DumpAnalysis(w io.Writer, tables []*Table) error {
+	a := Analyze(tables)
+	fmt.Fprintf(w, "Tables: %d, Relations: %d\n", a.TableCount, a.RelationCount)
+
+	writeList := func(title string, items []string) {
+		fmt.Fprintf(w, "\n%s:\n", title)
+		if len(items) == 0 {
+			io.WriteString(w, "  (none)\n")
+		}
+		for _, item := range items {
+			fmt.Fprintf(w, "  - %s\n", item)
+		}
+	}
+	joinAll := func(lists [][]string, sep string) []string {
+		var result []string
+		for _, l := range lists {
+			result = append(result, strings.Join(l, sep))
+		}
+		return result
+	}
+
+	writeList("Orphan tables", a.Orphans)
+	writeList("FK cycles", joinAll(a.Cycles, ", "))
+	writeList("NOT NULL FK cycles (rows can't be inserted)", joinAll(a.NotNullCycles, ", "))
+	writeList("Longest dependency chains", joinAll(a.LongestChains, " -> "))
+
+	width := len("Table")
+	for _, s := range a.Tables {
+		if len(s.Name) > width {
+			width = len(s.Name)
+		}
+	}
+	fmt.Fprintf(w, "\nFan-in/Fan-out:\n  %-*s %6s %6s\n", width, "Table", "In", "Out")
+	for _, s := range a.Tables {
+		fmt.Fprintf(w, "  %-*s %6d %6d\n", width, s.Name, s.FanIn, s.FanOut)
+	}
+	return nil
+}
+
+// DumpAnalysisJSON writes the analysis report as JSON.
+func DumpAnalysisJSON(w io.Writer, tables []*Table) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(Analyze(tables))
+}
@@ -0,0 +1,118 @@
+package md2sql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	tables, err := Parse(strings.NewReader(TrimIndent(t, `
+	* table: Company
+	  * @id
+	* table: Department
+	  * @id
+	  * company: *Company.id
+	  * manager: *Employee.id?
+	* table: Employee
+	  * @id
+	  * department: *Department.id
+	  * boss: *Employee.id?
+	  * skills: *Skill.id[]
+	* table: Skill
+	  * @id
+	* table: Invoice
+	  * @id
+	  * order: *Order.id
+	* table: Order
+	  * @id
+	  * invoice: *Invoice.id
+	* table: Log
+	  * @id
+	`)))
+	assert.NoError(t, err)
+
+	a := Analyze(tables)
+	assert.Equal(t, 7, a.TableCount)
+	assert.Equal(t, 7, a.RelationCount)
+	assert.Equal(t, []string{"Log"}, a.Orphans)
+	assert.Equal(t, [][]string{{"Department", "Employee"}, {"Invoice", "Order"}}, a.Cycles)
+	assert.Equal(t, [][]string{{"Invoice", "Order"}}, a.NotNullCycles)
+	assert.Equal(t, [][]string{{"Employee", "Department", "Company"}}, a.LongestChains)
+	assert.Equal(t, []TableStats{
+		{Name: "Company", FanIn: 1, FanOut: 0},
+		{Name: "Department", FanIn: 1, FanOut: 2},
+		{Name: "Employee", FanIn: 2, FanOut: 3},
+		{Name: "Skill", FanIn: 1, FanOut: 0},
+		{Name: "Invoice", FanIn: 1, FanOut: 1},
+		{Name: "Order", FanIn: 1, FanOut: 1},
+		{Name: "Log", FanIn: 0, FanOut: 0},
+	}, a.Tables)
+}
+
+func TestAnalyze_Chain(t *testing.T) {
+	tables, err := Parse(strings.NewReader(TrimIndent(t, `
+	* table: OrderItem
+	  * @id
+	  * order: *Order.id
+	  * product: *Product.id
+	* table: Order
+	  * @id
+	  * user: *User.id
+	* table: Product
+	  * @id
+	* table: User
+	  * @id
+	  * self: *User.id?
+	`)))
+	assert.NoError(t, err)
+
+	a := Analyze(tables)
+	assert.Equal(t, [][]string{{"User"}}, a.Cycles)
+	assert.Equal(t, [][]string{}, a.NotNullCycles)
+	assert.Equal(t, [][]string{{"OrderItem", "Order", "User"}}, a.LongestChains)
+}
+
+func TestDumpAnalysis(t *testing.T) {
+	tables, err := Parse(strings.NewReader(TrimIndent(t, `
+	* table: User
+	  * @id
+	  * job: *Job.id
+	* table: Job
+	  * @id
+	* table: Log
+	  * @id
+	`)))
+	assert.NoError(t, err)
+
+	w := &bytes.Buffer{}
+	assert.NoError(t, DumpAnalysis(w, tables))
+	assert.Equal(t, TrimIndent(t, `
+	Tables: 3, Relations: 1
+
+	Orphan tables:
+	  - Log
+
+	FK cycles:
+	  (none)
+
+	NOT NULL FK cycles (rows can't be inserted):
+	  (none)
+
+	Longest dependency chains:
+	  - User -> Job
+
+	Fan-in/Fan-out:
+	  Table     In    Out
+	  User       0      1
+	  Job        1      0
+	  Log        0      0
+	`)+"\n", w.String())
+
+	w.Reset()
+	assert.NoError(t, DumpAnalysisJSON(w, tables))
+	assert.Contains(t, w.String(), `"orphans": [`+"\n"+`    "Log"`)
+	assert.Contains(t, w.String(), `"cycles": [],`)
+}
@@ -0,0 +1,250 @@
+package md2sql
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// atlasTypePattern matches the types that Atlas HCL accepts without sql() like "varchar(100)" and "decimal(10,2)".
+var atlasTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\(\d+(,\s*\d+)?\))?$`)
+
+// atlasAttr is an attribute or a nested block (value is empty and body has the lines) of Atlas HCL.
+type atlasAttr struct {
+	key   string
+	value string
+	body  []atlasAttr
+}
+
+// DumpAtlas writes the tables as the schema of Atlas HCL that "atlas schema apply" reads.
+// Column types are the SQL types of the dialect. Foreign keys and indexes need names in HCL,
+// so the names of the dialect or StandardNamingConvention are used if the naming convention doesn't name them.
+func DumpAtlas(w io.Writer, tables []*Table, d Dialect, opts ...Option) error {
+	o := newOption(opts)
+	md := o.dialect(d)
+	if _, err := fixRelations(tables); err != nil {
+		return err
+	}
+	schema := o.schema
+	if schema == "" {
+		switch md.Dialect {
+		case SQLite:
+			schema = "main"
+		case SQLServer:
+			schema = "dbo"
+		default:
+			schema = "public"
+		}
+	}
+	a := &atlasWriter{w: w, d: md, o: o, schema: schema}
+	for _, t := range tables {
+		a.table(t)
+	}
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if c.LinkTable != "" && c.AssociativeEntity {
+				a.associativeTable(t, c)
+			}
+		}
+	}
+	fmt.Fprintf(w, "schema %s {\n}\n", strconv.Quote(schema))
+	return nil
+}
+
+type atlasWriter struct {
+	w      io.Writer
+	d      mappedDialect
+	o      *option
+	schema string
+}
+
+func (a *atlasWriter) table(t *Table) {
+	attrs := []atlasAttr{{key: "schema", value: "schema." + a.schema}}
+	if t.Description != "" {
+		attrs = append(attrs, atlasAttr{key: "comment", value: strconv.Quote(t.Description)})
+	}
+	strict := a.d.Capabilities().StrictTables && annotationFlag(t.Annotations, "strict")
+	if strict {
+		attrs = append(attrs, atlasAttr{key: "strict", value: "true"})
+	}
+	if a.d.Capabilities().StrictTables && annotationFlag(t.Annotations, "without_rowid") {
+		attrs = append(attrs, atlasAttr{key: "without_rowid", value: "true"})
+	}
+	var pks []string
+	for _, c := range t.Columns {
+		if c.AssociativeEntity {
+			continue
+		}
+		sqlType := a.d.columnType(c)
+		if strict {
+			sqlType = strictType(sqlType)
+		}
+		var body []atlasAttr
+		if c.PrimaryKey {
+			pks = append(pks, c.Name)
+			if c.AutoIncrement {
+				body = a.autoIncrement(c)
+			} else {
+				body = append([]atlasAttr{{key: "null", value: "false"}}, a.columnType(sqlType)...)
+			}
+		} else {
+			body = append([]atlasAttr{{key: "null", value: strconv.FormatBool(c.Nullable)}}, a.columnType(sqlType)...)
+		}
+		attrs = append(attrs, atlasAttr{key: "column " + strconv.Quote(c.Name), body: body})
+	}
+	if len(pks) > 0 {
+		attrs = append(attrs, a.primaryKey(pks))
+	}
+	for _, fk := range foreignKeys(t) {
+		attrs = append(attrs, a.foreignKey(fk))
+	}
+	for _, c := range t.Columns {
+		if c.Index {
+			template := firstTemplate(a.o.naming.Unique, a.d.ConstraintNaming().Unique, StandardNamingConvention.Unique)
+			name := expandName(a.d.Dialect, template, t.Name, []string{c.Name}, "", nil)
+			attrs = append(attrs, atlasAttr{key: "index " + strconv.Quote(name), body: []atlasAttr{
+				{key: "unique", value: "true"},
+				{key: "columns", value: "[column." + c.Name + "]"},
+			}})
+		}
+	}
+	a.block("table "+strconv.Quote(t.Name), attrs)
+}
+
+// associativeTable writes the table of the associative entity column c of the table t like createAssociativeTable.
+func (a *atlasWriter) associativeTable(t *Table, c *Column) {
+	name := t.Name + "_" + c.Name
+	attrs := []atlasAttr{{key: "schema", value: "schema." + a.schema}}
+	attrs = append(attrs, atlasAttr{key: `column "id"`, body: a.autoIncrement(&Column{Name: "id", PrimaryKey: true, AutoIncrement: true})})
+	var pks, fks []string
+	for _, pk := range t.Columns {
+		if pk.PrimaryKey {
+			pks = append(pks, pk.Name)
+			fks = append(fks, t.Name+"_"+pk.Name)
+			body := append([]atlasAttr{{key: "null", value: "false"}}, a.columnType(a.d.baseType(pk))...)
+			attrs = append(attrs, atlasAttr{key: "column " + strconv.Quote(t.Name+"_"+pk.Name), body: body})
+		}
+	}
+	link := c.LinkTable + "_" + c.LinkColumn
+	body := append([]atlasAttr{{key: "null", value: "false"}}, a.columnType(a.d.baseType(c))...)
+	attrs = append(attrs, atlasAttr{key: "column " + strconv.Quote(link), body: body})
+	attrs = append(attrs, a.primaryKey([]string{"id"}))
+	attrs = append(attrs,
+		a.foreignKey(&foreignKey{Table: name, Columns: fks, RefTable: t.Name, RefColumns: pks}),
+		a.foreignKey(&foreignKey{Table: name, Columns: []string{link}, RefTable: c.LinkTable, RefColumns: []string{c.LinkColumn}}))
+	a.block("table "+strconv.Quote(name), attrs)
+}
+
+// columnType returns the type attributes of the column. UNSIGNED of MySQL is the separate attribute.
+func (a *atlasWriter) columnType(sqlType string) []atlasAttr {
+	t := strings.ToLower(sqlType)
+	var unsigned bool
+	if strings.HasSuffix(t, " unsigned") {
+		t = strings.TrimSuffix(t, " unsigned")
+		unsigned = true
+	}
+	var result []atlasAttr
+	if atlasTypePattern.MatchString(t) {
+		result = append(result, atlasAttr{key: "type", value: t})
+	} else {
+		result = append(result, atlasAttr{key: "type", value: "sql(" + strconv.Quote(sqlType) + ")"})
+		unsigned = false
+	}
+	if unsigned {
+		result = append(result, atlasAttr{key: "unsigned", value: "true"})
+	}
+	return result
+}
+
+// autoIncrement returns the attributes of the auto-increment key column.
+// Pseudo types like SERIAL are only written on PostgreSQL and the others use the type and the modifier.
+func (a *atlasWriter) autoIncrement(c *Column) []atlasAttr {
+	result := []atlasAttr{{key: "null", value: "false"}}
+	ai, ok := a.d.identityOf(c)
+	if !ok {
+		ai, _ = a.d.Identity("")
+	}
+	if ai.Pseudo != "" && a.d.Dialect == PostgreSQL {
+		return append(result, a.columnType(ai.Pseudo)...)
+	}
+	result = append(result, a.columnType(ai.Type)...)
+	modifier := strings.ToUpper(ai.Modifier)
+	switch {
+	case ai.Pseudo != "" || modifier == "AUTO_INCREMENT" || modifier == "AUTOINCREMENT":
+		result = append(result, atlasAttr{key: "auto_increment", value: "true"})
+	case strings.HasSuffix(modifier, "AS IDENTITY"):
+		generated := "BY_DEFAULT"
+		if strings.Contains(modifier, "ALWAYS") {
+			generated = "ALWAYS"
+		}
+		result = append(result, atlasAttr{key: "identity", body: []atlasAttr{{key: "generated", value: generated}}})
+	case strings.HasPrefix(modifier, "IDENTITY"):
+		result = append(result, atlasAttr{key: "identity", body: []atlasAttr{{key: "seed", value: "1"}, {key: "increment", value: "1"}}})
+	case strings.HasPrefix(modifier, "DEFAULT "):
+		function := strings.TrimSpace(ai.Modifier[len("DEFAULT "):])
+		result = append(result, atlasAttr{key: "default", value: "sql(" + strconv.Quote(function) + ")"})
+	}
+	return result
+}
+
+func (a *atlasWriter) primaryKey(pks []string) atlasAttr {
+	return atlasAttr{key: "primary_key", body: []atlasAttr{{key: "columns", value: atlasColumns("column.", pks)}}}
+}
+
+func (a *atlasWriter) foreignKey(fk *foreignKey) atlasAttr {
+	template := firstTemplate(a.o.naming.ForeignKey, a.d.ConstraintNaming().ForeignKey, StandardNamingConvention.ForeignKey)
+	name := expandName(a.d.Dialect, template, fk.Table, fk.Columns, fk.RefTable, fk.RefColumns)
+	return atlasAttr{key: "foreign_key " + strconv.Quote(name), body: []atlasAttr{
+		{key: "columns", value: atlasColumns("column.", fk.Columns)},
+		{key: "ref_columns", value: atlasColumns("table."+fk.RefTable+".column.", fk.RefColumns)},
+	}}
+}
+
+// firstTemplate returns the first name template that isn't empty.
+func firstTemplate(templates ...string) string {
+	for _, template := range templates {
+		if template != "" {
+			return template
+		}
+	}
+	return ""
+}
+
+func atlasColumns(prefix string, columns []string) string {
+	refs := make([]string, len(columns))
+	for i, c := range columns {
+		refs[i] = prefix + c
+	}
+	return "[" + strings.Join(refs, ", ") + "]"
+}
+
+// block writes the block with the attributes. "=" of the consecutive attributes are aligned like "atlas schema fmt".
+func (a *atlasWriter) block(header string, attrs []atlasAttr) {
+	fmt.Fprintf(a.w, "%s {\n", header)
+	writeAtlasAttrs(a.w, "  ", attrs)
+	io.WriteString(a.w, "}\n\n")
+}
+
+func writeAtlasAttrs(w io.Writer, indent string, attrs []atlasAttr) {
+	for i := 0; i < len(attrs); {
+		if attrs[i].body != nil {
+			fmt.Fprintf(w, "%s%s {\n", indent, attrs[i].key)
+			writeAtlasAttrs(w, indent+"  ", attrs[i].body)
+			fmt.Fprintf(w, "%s}\n", indent)
+			i++
+			continue
+		}
+		j := i
+		width := 0
+		for ; j < len(attrs) && attrs[j].body == nil; j++ {
+			if len(attrs[j].key) > width {
+				width = len(attrs[j].key)
+			}
+		}
+		for ; i < j; i++ {
+			fmt.Fprintf(w, "%s%-*s = %s\n", indent, width, attrs[i].key, attrs[i].value)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package md2sql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpAtlas(t *testing.T) {
+	type args struct {
+		src     string
+		dialect Dialect
+		opts    []Option
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "PostgreSQL",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  Users who signed up.
+				  * @id
+				  * $email: varchar(100)
+				  * rate: double?
+				  * job: *Job.id?
+				* table: Job
+				  * @id
+				`),
+				dialect: PostgreSQL,
+			},
+			want: TrimIndent(t, `
+			table "User" {
+			  schema  = schema.public
+			  comment = "Users who signed up."
+			  column "id" {
+			    null = false
+			    type = serial
+			  }
+			  column "email" {
+			    null = false
+			    type = varchar(100)
+			  }
+			  column "rate" {
+			    null = true
+			    type = sql("DOUBLE PRECISION")
+			  }
+			  column "job" {
+			    null = true
+			    type = integer
+			  }
+			  primary_key {
+			    columns = [column.id]
+			  }
+			  foreign_key "User_job_fkey" {
+			    columns     = [column.job]
+			    ref_columns = [table.Job.column.id]
+			  }
+			  index "INDEX_User_email" {
+			    unique  = true
+			    columns = [column.email]
+			  }
+			}
+
+			table "Job" {
+			  schema = schema.public
+			  column "id" {
+			    null = false
+			    type = serial
+			  }
+			  primary_key {
+			    columns = [column.id]
+			  }
+			}
+
+			schema "public" {
+			}
+			`),
+		},
+		{
+			name: "MySQL with associative entity",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * tags: *Tag.id[]
+				* table: Tag
+				  * @id
+				`),
+				dialect: MySQL,
+				opts:    []Option{WithSchema("app")},
+			},
+			want: TrimIndent(t, `
+			table "User" {
+			  schema = schema.app
+			  column "id" {
+			    null           = false
+			    type           = bigint
+			    unsigned       = true
+			    auto_increment = true
+			  }
+			  primary_key {
+			    columns = [column.id]
+			  }
+			}
+
+			table "Tag" {
+			  schema = schema.app
+			  column "id" {
+			    null           = false
+			    type           = bigint
+			    unsigned       = true
+			    auto_increment = true
+			  }
+			  primary_key {
+			    columns = [column.id]
+			  }
+			}
+
+			table "User_tags" {
+			  schema = schema.app
+			  column "id" {
+			    null           = false
+			    type           = bigint
+			    unsigned       = true
+			    auto_increment = true
+			  }
+			  column "User_id" {
+			    null     = false
+			    type     = bigint
+			    unsigned = true
+			  }
+			  column "Tag_id" {
+			    null     = false
+			    type     = bigint
+			    unsigned = true
+			  }
+			  primary_key {
+			    columns = [column.id]
+			  }
+			  foreign_key "fk_User_tags_User_id_User" {
+			    columns     = [column.User_id]
+			    ref_columns = [table.User.column.id]
+			  }
+			  foreign_key "fk_User_tags_Tag_id_Tag" {
+			    columns     = [column.Tag_id]
+			    ref_columns = [table.Tag.column.id]
+			  }
+			}
+
+			schema "app" {
+			}
+			`),
+		},
+		{
+			name: "SQLite strict table",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Tag
+				  * !strict
+				  * !without_rowid
+				  * @code: varchar(10)
+				  * at: timestamp?
+				`),
+				dialect: SQLite,
+			},
+			want: TrimIndent(t, `
+			table "Tag" {
+			  schema        = schema.main
+			  strict        = true
+			  without_rowid = true
+			  column "code" {
+			    null = false
+			    type = text
+			  }
+			  column "at" {
+			    null = true
+			    type = text
+			  }
+			  primary_key {
+			    columns = [column.code]
+			  }
+			}
+
+			schema "main" {
+			}
+			`),
+		},
+		{
+			name: "identity",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				`),
+				dialect: PostgreSQL,
+				opts:    []Option{WithIdentity("identity-always")},
+			},
+			want: TrimIndent(t, `
+			table "User" {
+			  schema = schema.public
+			  column "id" {
+			    null = false
+			    type = bigint
+			    identity {
+			      generated = ALWAYS
+			    }
+			  }
+			  primary_key {
+			    columns = [column.id]
+			  }
+			}
+
+			schema "public" {
+			}
+			`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := Parse(strings.NewReader(tt.args.src))
+			assert.NoError(t, err)
+			var out bytes.Buffer
+			err = DumpAtlas(&out, tables, tt.args.dialect, tt.args.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want+"\n", out.String())
+		})
+	}
+}
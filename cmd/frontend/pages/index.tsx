@@ -164,6 +164,13 @@ const Home: NextPage = () => {
                     <option value="postgres">PostgreSQL</option>
                     <option value="mysql">MySQL</option>
                     <option value="sqlite">SQLite</option>
+                    <option value="sqlserver">SQL Server</option>
+                    <option value="oracle">Oracle</option>
+                    <option value="oracle11g">Oracle 11g</option>
+                    <option value="duckdb">DuckDB</option>
+                    <option value="spanner">Cloud Spanner</option>
+                    <option value="clickhouse">ClickHouse</option>
+                    <option value="bigquery">BigQuery</option>
                   </select>
                 </div>
               : null
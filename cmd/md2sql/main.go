@@ -1,13 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"database/sql"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/exec"
 
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
 	"github.com/shibukawa/md2sql"
 	"gopkg.in/alecthomas/kingpin.v2"
+	_ "modernc.org/sqlite"
 )
 
 func init() {
@@ -16,10 +22,50 @@ func init() {
 }
 
 var (
-	dialect = kingpin.Flag("dialect", "SQL dialect").Short('d').Default("postgres").Enum("postgres", "mysql", "sqlite")
-	format  = kingpin.Flag("format", "Output format").Short('f').Default("sql").Enum("sql", "mermaid", "plantuml", "graphviz", "dot")
+	dialect = kingpin.Flag("dialect", "SQL dialect").Short('d').Default("postgres").Enum(md2sql.DialectNames()...)
 	output  = kingpin.Flag("output", "Output file").Short('o').File()
-	source  = kingpin.Arg("src", "source file").ExistingFile()
+
+	generate = kingpin.Command("generate", "Generate SQL or diagrams from Markdown").Default()
+	format   = generate.Flag("format", "Output format").Short('f').Default("sql").Enum("sql", "mermaid", "plantuml", "graphviz", "dot", "analysis", "analysis-json", "atlas", "liquibase", "liquibase-xml", "prisma", "dbml")
+	source   = generate.Arg("src", "source file").ExistingFile()
+
+	diff      = kingpin.Command("diff", "Generate ALTER TABLE migration SQL from two versions of Markdown")
+	diffFiles = diff.Arg("files", "old and new source files, or the source file with --rev").Required().Strings()
+	diffRev   = diff.Flag("rev", "git revision of the old version of the source file").String()
+
+	importCmd    = kingpin.Command("import", "Generate Markdown from SQL DDL or a database")
+	importSource = importCmd.Arg("src", "SQL file").ExistingFile()
+	importDriver = importCmd.Flag("driver", "Database driver to read the tables from instead of SQL file").Enum(driverNames()...)
+	importDSN    = importCmd.Flag("dsn", "Data source name of the database").String()
+
+	migrate          = kingpin.Command("migrate", "Write the next numbered migration files when Markdown is changed")
+	migrateSource    = migrate.Arg("src", "source file").Required().ExistingFile()
+	migrateDir       = migrate.Flag("dir", "Migration directory that has the snapshot of the last model").Default("migrations").String()
+	migrateName      = migrate.Flag("name", "Name of the migration").Default("md2sql").String()
+	migrationFormats = map[string]md2sql.MigrationFormat{
+		"golang-migrate": md2sql.GolangMigrate,
+		"goose":          md2sql.Goose,
+	}
+	migrationFormat = migrate.Flag("migration-format", "Migration file format").Default("golang-migrate").Enum("golang-migrate", "goose")
+
+	drift          = kingpin.Command("drift", "Report differences between a database and Markdown, and exit with 1 if they differ")
+	driftSource    = drift.Arg("src", "source file").Required().ExistingFile()
+	driftDriver    = drift.Flag("driver", "Database driver").Required().Enum(driverNames()...)
+	driftDSN       = drift.Flag("dsn", "Data source name of the database").String()
+	driftMigration = drift.Flag("migration", "Write the migration SQL that fixes the database after the report").Bool()
+
+	sourceOrder = kingpin.Flag("source-order", "Keep table order of the source in SQL").Bool()
+	naming      = kingpin.Flag("naming", "Naming convention of constraints").Default("default").Enum("default", "standard")
+	pkName      = kingpin.Flag("pk-name", "Name template of primary keys (e.g. pk_{table})").String()
+	fkName      = kingpin.Flag("fk-name", "Name template of foreign keys (e.g. fk_{table}_{columns}_{ref})").String()
+	uniqueName  = kingpin.Flag("unique-name", "Name template of unique indexes (e.g. uq_{table}_{columns})").String()
+	configFile  = kingpin.Flag("config", "Config file (YAML) to override type mapping").Short('c').ExistingFile()
+	bitReversed = kingpin.Flag("bit-reversed-sequence", "Use INT64 keys with bit-reversed sequences instead of UUID (Spanner)").Bool()
+	dataset     = kingpin.Flag("dataset", "Dataset that qualifies table names (BigQuery)").String()
+	schema      = kingpin.Flag("schema", "Schema name of Atlas HCL (default: public, main on SQLite, dbo on SQL Server)").String()
+	ifNotExists = kingpin.Flag("if-not-exists", "Write CREATE TABLE IF NOT EXISTS and CREATE INDEX IF NOT EXISTS").Bool()
+	dropTables  = kingpin.Flag("drop-tables", "Write DROP TABLE IF EXISTS of all tables before creating them").Bool()
+	identity    = kingpin.Flag("identity", "Identity strategy of auto-increment keys (PostgreSQL: serial, bigserial, identity, identity-always, uuid, uuidv7, ulid)").String()
 )
 
 var dummy = `
@@ -35,7 +81,7 @@ var dummy = `
 `
 
 func main() {
-	kingpin.Parse()
+	command := kingpin.Parse()
 
 	if *output == nil {
 		output = &os.Stdout
@@ -43,8 +89,23 @@ func main() {
 		defer (*output).Close()
 	}
 
+	switch command {
+	case generate.FullCommand():
+		runGenerate()
+	case diff.FullCommand():
+		runDiff()
+	case migrate.FullCommand():
+		runMigrate()
+	case drift.FullCommand():
+		runDrift()
+	case importCmd.FullCommand():
+		runImport()
+	}
+}
+
+func runGenerate() {
 	var src io.Reader
-	if source == nil {
+	if *source == "" {
 		src = os.Stdin
 	} else {
 		sf, err := os.Open(*source)
@@ -56,22 +117,274 @@ func main() {
 		src = sf
 	}
 
-	tables, err := md2sql.Parse(src)
+	tables, config, err := md2sql.ParseWithConfig(src)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "parse error: %s", err.Error())
 		os.Exit(1)
 	}
 	d := md2sql.ToDialect(*dialect)
+	opts := options(config)
+	for _, w := range md2sql.CheckTypes(tables, d, opts...) {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
 	switch *format {
 	case "sql":
-		md2sql.DumpSQL(*output, tables, d)
+		err = md2sql.DumpSQL(*output, tables, d, opts...)
 	case "mermaid":
-		md2sql.DumpMermaid(*output, tables, d)
+		err = md2sql.DumpMermaid(*output, tables, d, opts...)
 	case "plantuml":
-		md2sql.DumpPlantUML(*output, tables, d)
+		err = md2sql.DumpPlantUML(*output, tables, d, opts...)
 	case "graphviz":
 		fallthrough
 	case "dot":
-		md2sql.DumpGraphviz(*output, tables, md2sql.PhysicalModel, d)
+		err = md2sql.DumpGraphviz(*output, tables, md2sql.PhysicalModel, d, opts...)
+	case "analysis":
+		err = md2sql.DumpAnalysis(*output, tables)
+	case "analysis-json":
+		err = md2sql.DumpAnalysisJSON(*output, tables)
+	case "atlas":
+		err = md2sql.DumpAtlas(*output, tables, d, opts...)
+	case "liquibase":
+		err = md2sql.DumpLiquibase(*output, tables, d, opts...)
+	case "liquibase-xml":
+		err = md2sql.DumpLiquibaseXML(*output, tables, d, opts...)
+	case "prisma":
+		err = md2sql.DumpPrisma(*output, tables, d, opts...)
+	case "dbml":
+		err = md2sql.DumpDBML(*output, tables, d, opts...)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "generate error: %s", err.Error())
+		os.Exit(1)
+	}
+}
+
+func runDiff() {
+	var oldSrc, newSrc []byte
+	var err error
+	if *diffRev != "" {
+		if len(*diffFiles) != 1 {
+			fmt.Fprintf(os.Stderr, "diff with --rev needs one source file")
+			os.Exit(1)
+		}
+		git := exec.Command("git", "show", *diffRev+":./"+(*diffFiles)[0])
+		git.Stderr = os.Stderr
+		oldSrc, err = git.Output()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "git show error: %s", err.Error())
+			os.Exit(1)
+		}
+		newSrc, err = os.ReadFile((*diffFiles)[0])
+	} else {
+		if len(*diffFiles) != 2 {
+			fmt.Fprintf(os.Stderr, "diff needs old and new source files")
+			os.Exit(1)
+		}
+		oldSrc, err = os.ReadFile((*diffFiles)[0])
+		if err == nil {
+			newSrc, err = os.ReadFile((*diffFiles)[1])
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "file open error: %s", err.Error())
+		os.Exit(1)
+	}
+
+	oldTables, _, err := md2sql.ParseWithConfig(bytes.NewReader(oldSrc))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "parse error: %s", err.Error())
+		os.Exit(1)
+	}
+	newTables, config, err := md2sql.ParseWithConfig(bytes.NewReader(newSrc))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "parse error: %s", err.Error())
+		os.Exit(1)
+	}
+	d := md2sql.ToDialect(*dialect)
+	opts := options(config)
+	for _, w := range md2sql.CheckTypes(newTables, d, opts...) {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	if err := md2sql.DumpMigration(*output, oldTables, newTables, d, opts...); err != nil {
+		fmt.Fprintf(os.Stderr, "diff error: %s", err.Error())
+		os.Exit(1)
+	}
+}
+
+func runMigrate() {
+	sf, err := os.Open(*migrateSource)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "file open error: %s", err.Error())
+		os.Exit(1)
+	}
+	defer sf.Close()
+	tables, config, err := md2sql.ParseWithConfig(sf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "parse error: %s", err.Error())
+		os.Exit(1)
+	}
+	d := md2sql.ToDialect(*dialect)
+	opts := options(config)
+	for _, w := range md2sql.CheckTypes(tables, d, opts...) {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	paths, err := md2sql.WriteMigration(*migrateDir, *migrateName, tables, d, migrationFormats[*migrationFormat], opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migration error: %s", err.Error())
+		os.Exit(1)
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "no changes")
+	}
+	for _, path := range paths {
+		fmt.Fprintln(*output, path)
+	}
+}
+
+func runDrift() {
+	sf, err := os.Open(*driftSource)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "file open error: %s", err.Error())
+		os.Exit(1)
+	}
+	defer sf.Close()
+	tables, config, err := md2sql.ParseWithConfig(sf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "parse error: %s", err.Error())
+		os.Exit(1)
+	}
+	db := openDB(*driftDriver, *driftDSN)
+	defer db.Close()
+	d := drivers[*driftDriver]
+	opts := options(config)
+	diff, err := md2sql.Drift(db, tables, d, opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "introspection error: %s", err.Error())
+		os.Exit(1)
+	}
+	md2sql.DumpDrift(*output, diff)
+	if diff.IsEmpty() {
+		return
+	}
+	if *driftMigration {
+		io.WriteString(*output, "\n")
+		if err := diff.DumpSQL(*output, d, opts...); err != nil {
+			fmt.Fprintf(os.Stderr, "diff error: %s", err.Error())
+		}
+		io.WriteString(*output, "\n")
+	}
+	os.Exit(1)
+}
+
+// drivers are the database/sql drivers and their dialects for introspection.
+var drivers = map[string]md2sql.Dialect{
+	"sqlite":   md2sql.SQLite,
+	"postgres": md2sql.PostgreSQL,
+	"mysql":    md2sql.MySQL,
+}
+
+func driverNames() []string {
+	return []string{"sqlite", "postgres", "mysql"}
+}
+
+// openDB opens the database and checks the connection.
+func openDB(driver, dsn string) *sql.DB {
+	db, err := sql.Open(driver, dsn)
+	if err == nil {
+		err = db.Ping()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "database open error: %s", err.Error())
+		os.Exit(1)
+	}
+	return db
+}
+
+func runImport() {
+	if *importDriver != "" {
+		db := openDB(*importDriver, *importDSN)
+		defer db.Close()
+		tables, err := md2sql.Introspect(db, drivers[*importDriver])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "introspection error: %s", err.Error())
+			os.Exit(1)
+		}
+		md2sql.DumpMarkdown(*output, tables)
+		return
+	}
+	var src io.Reader
+	if *importSource == "" {
+		src = os.Stdin
+	} else {
+		sf, err := os.Open(*importSource)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "file open error: %s", err.Error())
+			os.Exit(1)
+		}
+		defer sf.Close()
+		src = sf
+	}
+	tables, err := md2sql.ParseSQL(src, md2sql.ToDialect(*dialect))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "parse error: %s", err.Error())
+		os.Exit(1)
+	}
+	md2sql.DumpMarkdown(*output, tables)
+}
+
+// options returns the options from the front matter, the config file and the flags.
+func options(config *md2sql.Config) []md2sql.Option {
+	opts := config.Options()
+	if *configFile != "" {
+		cf, err := os.Open(*configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "file open error: %s", err.Error())
+			os.Exit(1)
+		}
+		defer cf.Close()
+		c, err := md2sql.ParseConfig(cf)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s", err.Error())
+			os.Exit(1)
+		}
+		opts = append(opts, c.Options()...)
+	}
+	if *sourceOrder {
+		opts = append(opts, md2sql.WithSourceOrder())
+	}
+	if *bitReversed {
+		opts = append(opts, md2sql.WithBitReversedSequence())
+	}
+	if *dataset != "" {
+		opts = append(opts, md2sql.WithDataset(*dataset))
+	}
+	if *schema != "" {
+		opts = append(opts, md2sql.WithSchema(*schema))
+	}
+	if *identity != "" {
+		opts = append(opts, md2sql.WithIdentity(*identity))
+	}
+	if *ifNotExists {
+		opts = append(opts, md2sql.WithIfNotExists())
+	}
+	if *dropTables {
+		opts = append(opts, md2sql.WithDropTables())
+	}
+	nc := md2sql.DefaultNamingConvention
+	if *naming == "standard" {
+		nc = md2sql.StandardNamingConvention
+	}
+	for _, t := range []struct {
+		flag     string
+		template *string
+	}{
+		{*pkName, &nc.PrimaryKey},
+		{*fkName, &nc.ForeignKey},
+		{*uniqueName, &nc.Unique},
+	} {
+		if t.flag != "" {
+			*t.template = t.flag
+		}
 	}
+	return append(opts, md2sql.WithNamingConvention(nc))
 }
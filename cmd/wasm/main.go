@@ -21,7 +21,7 @@ func ConvertToSQL(this js.Value, args []js.Value) any {
 	if len(args) == 2 {
 		dialect = args[1].String()
 	}
-	tables, err := md2sql.Parse(strings.NewReader(args[0].String()))
+	tables, config, err := md2sql.ParseWithConfig(strings.NewReader(args[0].String()))
 	if err != nil {
 		return map[string]any{
 			"ok":      false,
@@ -29,7 +29,7 @@ func ConvertToSQL(this js.Value, args []js.Value) any {
 		}
 	}
 	var buf bytes.Buffer
-	md2sql.DumpSQL(&buf, tables, md2sql.ToDialect(dialect))
+	md2sql.DumpSQL(&buf, tables, md2sql.ToDialect(dialect), config.Options()...)
 	return map[string]any{
 		"ok":     true,
 		"result": buf.String(),
@@ -43,7 +43,7 @@ func ConvertToMermaid(this js.Value, args []js.Value) any {
 			"message": "first argument should be markdown source.",
 		}
 	}
-	tables, err := md2sql.Parse(strings.NewReader(args[0].String()))
+	tables, config, err := md2sql.ParseWithConfig(strings.NewReader(args[0].String()))
 	if err != nil {
 		return map[string]any{
 			"ok":      false,
@@ -51,7 +51,7 @@ func ConvertToMermaid(this js.Value, args []js.Value) any {
 		}
 	}
 	var buf bytes.Buffer
-	md2sql.DumpMermaid(&buf, tables, md2sql.PostgreSQL)
+	md2sql.DumpMermaid(&buf, tables, md2sql.PostgreSQL, config.Options()...)
 	return map[string]any{
 		"ok":     true,
 		"result": buf.String(),
@@ -65,7 +65,7 @@ func ConvertToPlantUML(this js.Value, args []js.Value) any {
 			"message": "first argument should be markdown source.",
 		}
 	}
-	tables, err := md2sql.Parse(strings.NewReader(args[0].String()))
+	tables, config, err := md2sql.ParseWithConfig(strings.NewReader(args[0].String()))
 	if err != nil {
 		return map[string]any{
 			"ok":      false,
@@ -73,7 +73,7 @@ func ConvertToPlantUML(this js.Value, args []js.Value) any {
 		}
 	}
 	var buf bytes.Buffer
-	md2sql.DumpPlantUML(&buf, tables, md2sql.PostgreSQL)
+	md2sql.DumpPlantUML(&buf, tables, md2sql.PostgreSQL, config.Options()...)
 	return map[string]any{
 		"ok":     true,
 		"result": buf.String(),
@@ -87,7 +87,7 @@ func ConvertToGraphviz(this js.Value, args []js.Value) any {
 			"message": "first argument should be markdown source.",
 		}
 	}
-	tables, err := md2sql.Parse(strings.NewReader(args[0].String()))
+	tables, config, err := md2sql.ParseWithConfig(strings.NewReader(args[0].String()))
 	if err != nil {
 		return map[string]any{
 			"ok":      false,
@@ -95,7 +95,7 @@ func ConvertToGraphviz(this js.Value, args []js.Value) any {
 		}
 	}
 	var buf bytes.Buffer
-	md2sql.DumpGraphviz(&buf, tables, md2sql.PhysicalModel, md2sql.PostgreSQL)
+	md2sql.DumpGraphviz(&buf, tables, md2sql.PhysicalModel, md2sql.PostgreSQL, config.Options()...)
 	return map[string]any{
 		"ok":     true,
 		"result": buf.String(),
@@ -0,0 +1,113 @@
+package md2sql
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the project configuration written in a YAML file or front matter of the Markdown.
+//
+//	types:
+//	  mysql:
+//	    string: VARCHAR(255)
+//	    autoincrement: BIGINT UNSIGNED AUTO_INCREMENT
+//	  postgres:
+//	    string: citext
+//	identity: uuid
+type Config struct {
+	Types TypeMapping
+	// Identity is the default identity strategy of auto-increment keys. See WithIdentity.
+	Identity string
+}
+
+type rawConfig struct {
+	Types    map[string]map[string]string `yaml:"types"`
+	Identity string                       `yaml:"identity"`
+}
+
+// ParseConfig reads YAML configuration file.
+func ParseConfig(r io.Reader) (*Config, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfig(b)
+}
+
+func parseConfig(b []byte) (*Config, error) {
+	var raw rawConfig
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("config parse error: %w", err)
+	}
+	result := &Config{
+		Types:    make(TypeMapping),
+		Identity: raw.Identity,
+	}
+	for name, types := range raw.Types {
+		d, ok := LookupDialect(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown dialect in type mapping: %s", name)
+		}
+		result.Types[d] = make(map[string]string)
+		for k, v := range types {
+			if strings.EqualFold(k, autoIncrementType) && strings.TrimSpace(v) == "" {
+				return nil, fmt.Errorf("empty autoincrement in type mapping of %s", name)
+			}
+			result.Types[d][strings.ToLower(k)] = v
+		}
+	}
+	return result, nil
+}
+
+// Options returns the options that apply the configuration to the Dump functions.
+func (c *Config) Options() []Option {
+	opts := []Option{WithTypeMapping(c.Types)}
+	if c.Identity != "" {
+		opts = append(opts, WithIdentity(c.Identity))
+	}
+	return opts
+}
+
+var frontMatterDelimiter = []byte("---")
+
+// splitFrontMatter splits YAML front matter surrounded by "---" lines from the Markdown.
+func splitFrontMatter(src []byte) (frontMatter, body []byte) {
+	if !bytes.HasPrefix(src, frontMatterDelimiter) {
+		return nil, src
+	}
+	lines := bytes.SplitAfter(src, []byte("\n"))
+	if len(bytes.TrimSpace(lines[0])) != len(frontMatterDelimiter) {
+		return nil, src
+	}
+	offset := len(lines[0])
+	for _, line := range lines[1:] {
+		if bytes.Equal(bytes.TrimSpace(line), frontMatterDelimiter) {
+			return src[len(lines[0]):offset], src[offset+len(line):]
+		}
+		offset += len(line)
+	}
+	return nil, src
+}
+
+// ParseWithConfig parses the Markdown and its front matter.
+// If there is no front matter, the returned Config is empty.
+func ParseWithConfig(r io.Reader) ([]*Table, *Config, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	frontMatter, body := splitFrontMatter(b)
+	config, err := parseConfig(frontMatter)
+	if err != nil {
+		return nil, nil, err
+	}
+	tables, err := parse(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tables, config, nil
+}
@@ -0,0 +1,200 @@
+package md2sql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig(strings.NewReader(TrimIndent(t, `
+	types:
+	  mysql:
+	    String: VARCHAR(255)
+	    autoincrement: BIGINT UNSIGNED AUTO_INCREMENT
+	  postgres:
+	    string: citext
+	identity: identity
+	`)))
+	assert.NoError(t, err)
+	assert.Equal(t, TypeMapping{
+		MySQL: {
+			"string":        "VARCHAR(255)",
+			"autoincrement": "BIGINT UNSIGNED AUTO_INCREMENT",
+		},
+		PostgreSQL: {
+			"string": "citext",
+		},
+	}, config.Types)
+	assert.Equal(t, "identity", config.Identity)
+}
+
+func TestParseConfig_UnknownDialect(t *testing.T) {
+	_, err := ParseConfig(strings.NewReader(TrimIndent(t, `
+	types:
+	  db2:
+	    string: VARCHAR(255)
+	`)))
+	assert.Error(t, err)
+}
+
+func TestParseConfig_EmptyAutoIncrement(t *testing.T) {
+	_, err := ParseConfig(strings.NewReader(TrimIndent(t, `
+	types:
+	  mysql:
+	    autoincrement: ""
+	`)))
+	assert.Error(t, err)
+}
+
+func TestParseWithConfig(t *testing.T) {
+	type args struct {
+		src string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantTables int
+		wantTypes  TypeMapping
+	}{
+		{
+			name: "with front matter",
+			args: args{
+				src: TrimIndent(t, `
+				---
+				types:
+				  sqlite:
+				    uuid: BLOB
+				---
+				# Model
+
+				* table: User
+				  * @id
+				`),
+			},
+			wantTables: 1,
+			wantTypes: TypeMapping{
+				SQLite: {"uuid": "BLOB"},
+			},
+		},
+		{
+			name: "without front matter",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+
+				---
+
+				* table: Job
+				  * @id
+				`),
+			},
+			wantTables: 2,
+			wantTypes:  TypeMapping{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, config, err := ParseWithConfig(strings.NewReader(tt.args.src))
+			assert.NoError(t, err)
+			assert.Len(t, tables, tt.wantTables)
+			assert.Equal(t, tt.wantTypes, config.Types)
+		})
+	}
+}
+
+func TestTypeMapping(t *testing.T) {
+	src := TrimIndent(t, `
+	* table: User
+	  * @id
+	  * name: string
+	  * email: varchar(100)?
+	  * job: *Job.id
+	* table: Job
+	  * @id
+	`)
+	mapping := WithTypeMapping(TypeMapping{
+		MySQL: {
+			"string":        "VARCHAR(255)",
+			"varchar":       "NVARCHAR({args})",
+			"autoincrement": "BIGINT UNSIGNED AUTO_INCREMENT",
+		},
+	})
+
+	t.Run("sql", func(t *testing.T) {
+		tables, err := Parse(strings.NewReader(src))
+		assert.NoError(t, err)
+		w := &bytes.Buffer{}
+		assert.NoError(t, DumpSQL(w, tables, MySQL, mapping))
+		assert.Equal(t, TrimIndent(t, `
+		CREATE TABLE Job(
+			id BIGINT UNSIGNED AUTO_INCREMENT,
+			PRIMARY KEY(id)
+		);
+
+		CREATE TABLE User(
+			id BIGINT UNSIGNED AUTO_INCREMENT,
+			name VARCHAR(255) NOT NULL,
+			email NVARCHAR(100),
+			job BIGINT UNSIGNED NOT NULL,
+			PRIMARY KEY(id),
+			FOREIGN KEY(job) REFERENCES Job(id)
+		);
+		`), w.String())
+	})
+
+	t.Run("mermaid", func(t *testing.T) {
+		tables, err := Parse(strings.NewReader(src))
+		assert.NoError(t, err)
+		w := &bytes.Buffer{}
+		assert.NoError(t, DumpMermaid(w, tables, MySQL, mapping))
+		assert.Contains(t, w.String(), "  VARCHAR(255) name\n")
+		assert.Contains(t, w.String(), "  BIGINT_UNSIGNED job FK\n")
+	})
+
+	t.Run("other dialects are not affected", func(t *testing.T) {
+		tables, err := Parse(strings.NewReader(src))
+		assert.NoError(t, err)
+		w := &bytes.Buffer{}
+		assert.NoError(t, DumpSQL(w, tables, PostgreSQL, mapping))
+		assert.Contains(t, w.String(), "\tname TEXT NOT NULL,\n")
+	})
+
+	t.Run("auto-increment", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			sqlType string
+			opts    []Option
+			key     string
+			ref     string
+		}{
+			{name: "type and modifier", sqlType: "BIGINT GENERATED ALWAYS AS IDENTITY", key: "BIGINT GENERATED ALWAYS AS IDENTITY", ref: "BIGINT"},
+			{name: "pseudo type", sqlType: "bigserial", key: "BIGSERIAL", ref: "BIGINT"},
+			{name: "type only", sqlType: "NUMERIC(20)", key: "NUMERIC(20)", ref: "NUMERIC(20)"},
+			{name: "empty", sqlType: " ", key: "SERIAL", ref: "INTEGER"},
+			{name: "identity wins", sqlType: "BIGSERIAL", opts: []Option{WithIdentity("uuid")}, key: "UUID DEFAULT gen_random_uuid()", ref: "UUID"},
+			{name: "unknown identity", sqlType: "BIGSERIAL", opts: []Option{WithIdentity("snowflake")}, key: "BIGSERIAL", ref: "BIGINT"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tables, err := Parse(strings.NewReader(src))
+				assert.NoError(t, err)
+				w := &bytes.Buffer{}
+				opts := append([]Option{WithTypeMapping(TypeMapping{PostgreSQL: {"autoincrement": tt.sqlType}})}, tt.opts...)
+				assert.NoError(t, DumpSQL(w, tables, PostgreSQL, opts...))
+				assert.Contains(t, w.String(), "\tid "+tt.key+",\n")
+				assert.Contains(t, w.String(), "\tjob "+tt.ref+" NOT NULL,\n")
+			})
+		}
+	})
+
+	t.Run("known type", func(t *testing.T) {
+		tables, err := Parse(strings.NewReader("* table: User\n  * area: geometry\n"))
+		assert.NoError(t, err)
+		assert.Len(t, CheckTypes(tables, PostgreSQL), 1)
+		assert.Len(t, CheckTypes(tables, PostgreSQL, WithTypeMapping(TypeMapping{PostgreSQL: {"geometry": "GEOMETRY"}})), 0)
+	})
+}
@@ -0,0 +1,166 @@
+package md2sql
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// dbmlNamePattern matches the names that DBML accepts without quotes.
+var dbmlNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DumpDBML writes the tables in DBML that dbdiagram.io reads. Column types are the SQL types of the dialect.
+// Refs are written from the foreign keys: ">" for foreign keys, "-" for unique foreign keys and "<>" for associative entities ("[]")
+// that refer the primary key of the owner table. Composite foreign keys are written like "Table.(a, b)", and associative entities
+// that refer the table itself are written as join tables because dbdiagram.io rejects "<>" to the same table. Tables that aren't plain entity tables are grouped by their types in TableGroups.
+func DumpDBML(w io.Writer, tables []*Table, d Dialect, opts ...Option) error {
+	md := newOption(opts).dialect(d)
+	if _, err := fixRelations(tables); err != nil {
+		return err
+	}
+	pks := primaryKeys(tables)
+	var joins []string
+	var refs []string
+	for i, t := range tables {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		fmt.Fprintf(w, "Table %s {\n", dbmlName(t.Name))
+		fks := foreignKeys(t)
+		for _, c := range t.Columns {
+			if c.AssociativeEntity {
+				if c.LinkTable == t.Name {
+					// dbdiagram.io rejects "<>" to the table itself, so the join table is written explicitly
+					join, joinRefs := dbmlJoinTable(md, t, c, pks[t.Name])
+					joins = append(joins, join)
+					refs = append(refs, joinRefs...)
+				} else {
+					// DBML makes the join table from the primary keys of both tables
+					refs = append(refs, fmt.Sprintf("Ref: %s.%s <> %s.%s", dbmlName(t.Name), dbmlColumns(pks[t.Name]), dbmlName(c.LinkTable), dbmlName(c.LinkColumn)))
+				}
+				continue
+			}
+			for _, fk := range fks {
+				if fk.Columns[0] == c.Name {
+					refs = append(refs, dbmlRef(t, fk, pks[t.Name]))
+				}
+			}
+			sqlType := md.columnType(c)
+			var settings []string
+			if c.PrimaryKey && len(pks[t.Name]) == 1 {
+				settings = append(settings, "pk")
+			}
+			if c.PrimaryKey && c.AutoIncrement {
+				settings = append(settings, "increment")
+			}
+			if !c.Nullable && (!c.PrimaryKey || len(pks[t.Name]) > 1) {
+				settings = append(settings, "not null")
+			}
+			if c.Index {
+				settings = append(settings, "unique")
+			}
+			fmt.Fprintf(w, "  %s %s", dbmlName(c.Name), dbmlType(sqlType))
+			if len(settings) > 0 {
+				fmt.Fprintf(w, " [%s]", strings.Join(settings, ", "))
+			}
+			io.WriteString(w, "\n")
+		}
+		if len(pks[t.Name]) > 1 {
+			fmt.Fprintf(w, "\n  indexes {\n    %s [pk]\n  }\n", dbmlColumns(pks[t.Name]))
+		}
+		if t.Description != "" {
+			fmt.Fprintf(w, "\n  Note: %s\n", dbmlString(t.Description))
+		}
+		io.WriteString(w, "}\n")
+	}
+	for _, join := range joins {
+		fmt.Fprintf(w, "\n%s", join)
+	}
+
+	if len(refs) > 0 {
+		fmt.Fprintf(w, "\n%s\n", strings.Join(refs, "\n"))
+	}
+
+	for tt := MasterTable; tt <= AssociativeEntity; tt++ {
+		var names []string
+		for _, t := range tables {
+			if t.Type == tt {
+				names = append(names, dbmlName(t.Name))
+			}
+		}
+		if len(names) > 0 {
+			fmt.Fprintf(w, "\nTableGroup %s {\n  %s\n}\n", tableType2label[tt], strings.Join(names, "\n  "))
+		}
+	}
+	return nil
+}
+
+// dbmlRef returns the Ref of the foreign key. Foreign keys that are unique or the primary key are one-to-one.
+func dbmlRef(t *Table, fk *foreignKey, pks []string) string {
+	kind := ">"
+	if equalStrings(fk.Columns, pks) {
+		kind = "-"
+	}
+	for _, c := range t.Columns {
+		if len(fk.Columns) == 1 && c.Name == fk.Columns[0] && c.Index {
+			kind = "-"
+		}
+	}
+	return fmt.Sprintf("Ref: %s.%s %s %s.%s", dbmlName(fk.Table), dbmlColumns(fk.Columns), kind, dbmlName(fk.RefTable), dbmlColumns(fk.RefColumns))
+}
+
+// dbmlJoinTable returns the join table of the associative entity that refers the table itself and its Refs.
+// The table is the same as DumpSQL creates except for the column of the other side, which is named after the associative column.
+func dbmlJoinTable(md mappedDialect, t *Table, c *Column, pks []string) (string, []string) {
+	name := t.Name + "_" + c.Name
+	associativeKey := &Column{Name: "id", PrimaryKey: true, AutoIncrement: true}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Table %s {\n  id %s [pk, increment]\n", dbmlName(name), dbmlType(md.baseType(associativeKey)))
+	owner := &foreignKey{Table: name, RefTable: t.Name, RefColumns: pks}
+	for _, pk := range t.Columns {
+		if pk.PrimaryKey {
+			owner.Columns = append(owner.Columns, t.Name+"_"+pk.Name)
+			fmt.Fprintf(&b, "  %s %s\n", dbmlName(t.Name+"_"+pk.Name), dbmlType(md.baseType(pk)))
+		}
+	}
+	other := &foreignKey{Table: name, Columns: []string{c.Name + "_" + c.LinkColumn}, RefTable: t.Name, RefColumns: []string{c.LinkColumn}}
+	fmt.Fprintf(&b, "  %s %s\n}\n", dbmlName(other.Columns[0]), dbmlType(md.baseType(c)))
+	return b.String(), []string{dbmlRef(&Table{Name: name}, owner, nil), dbmlRef(&Table{Name: name}, other, nil)}
+}
+
+// dbmlName quotes the name if it isn't an identifier.
+func dbmlName(name string) string {
+	if dbmlNamePattern.MatchString(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `\"`) + `"`
+}
+
+// dbmlType quotes the type that has spaces like "DOUBLE PRECISION".
+func dbmlType(sqlType string) string {
+	if strings.Contains(sqlType, " ") {
+		return `"` + sqlType + `"`
+	}
+	return sqlType
+}
+
+// dbmlColumns returns the column or the composite columns like "(a, b)".
+func dbmlColumns(columns []string) string {
+	if len(columns) == 1 {
+		return dbmlName(columns[0])
+	}
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = dbmlName(c)
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
+
+// dbmlString returns the string literal. Multi-line strings use triple quotes.
+func dbmlString(s string) string {
+	if strings.Contains(s, "\n") {
+		return "'''\n  " + strings.ReplaceAll(strings.ReplaceAll(s, `'''`, `\'''`), "\n", "\n  ") + "\n  '''"
+	}
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
@@ -0,0 +1,190 @@
+package md2sql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpDBML(t *testing.T) {
+	type args struct {
+		src     string
+		dialect Dialect
+		opts    []Option
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "relations and table groups",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  Users who signed up.
+				  * @id
+				  * $email: varchar(100)
+				  * rate: double?
+				  * job: *Job.id?
+				  * $profile: *Profile.id
+				  * tags: *Tag.id[]
+				* master: Job
+				  * @id
+				  * name: string
+				* master: Tag
+				  * @id
+				* table: Profile
+				  * @id
+				* tran: Login
+				  * @user: *User.id
+				  * @at: timestamp
+				`),
+				dialect: PostgreSQL,
+			},
+			want: TrimIndent(t, `
+			Table User {
+			  id INTEGER [pk, increment]
+			  email VARCHAR(100) [not null, unique]
+			  rate "DOUBLE PRECISION"
+			  job INTEGER
+			  profile INTEGER [not null, unique]
+
+			  Note: 'Users who signed up.'
+			}
+
+			Table Job {
+			  id INTEGER [pk, increment]
+			  name TEXT [not null]
+			}
+
+			Table Tag {
+			  id INTEGER [pk, increment]
+			}
+
+			Table Profile {
+			  id INTEGER [pk, increment]
+			}
+
+			Table Login {
+			  user INTEGER [not null]
+			  at TIMESTAMP [not null]
+
+			  indexes {
+			    (user, at) [pk]
+			  }
+			}
+
+			Ref: User.job > Job.id
+			Ref: User.profile - Profile.id
+			Ref: User.id <> Tag.id
+			Ref: Login.user > User.id
+
+			TableGroup master {
+			  Job
+			  Tag
+			}
+
+			TableGroup tran {
+			  Login
+			}
+			`),
+		},
+		{
+			name: "quoted names and multi-line note",
+			args: args{
+				src: TrimIndent(t, `
+				* table: 会員
+				  Members.
+				  It's the owner of orders.
+				  * @code: char(8)
+				  * boss: *会員.code?
+				`),
+				dialect: MySQL,
+			},
+			want: TrimIndent(t, `
+			Table "会員" {
+			  code CHAR(8) [pk]
+			  boss CHAR(8)
+
+			  Note: '''
+			  Members.
+			  It's the owner of orders.
+			  '''
+			}
+
+			Ref: "会員".boss > "会員".code
+			`),
+		},
+		{
+			name: "composite foreign key",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Pair
+				  * @left: integer
+				  * @right: integer
+				* table: Child
+				  * @id
+				  * left: *Pair.left
+				  * right: *Pair.right
+				`),
+				dialect: PostgreSQL,
+			},
+			want: TrimIndent(t, `
+			Table Pair {
+			  left INTEGER [not null]
+			  right INTEGER [not null]
+
+			  indexes {
+			    (left, right) [pk]
+			  }
+			}
+
+			Table Child {
+			  id INTEGER [pk, increment]
+			  left INTEGER [not null]
+			  right INTEGER [not null]
+			}
+
+			Ref: Child.(left, right) > Pair.(left, right)
+			`),
+		},
+		{
+			name: "self many-to-many",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Person
+				  * @id
+				  * friends: *Person.id[]
+				`),
+				dialect: PostgreSQL,
+			},
+			want: TrimIndent(t, `
+			Table Person {
+			  id INTEGER [pk, increment]
+			}
+
+			Table Person_friends {
+			  id INTEGER [pk, increment]
+			  Person_id INTEGER
+			  friends_id INTEGER
+			}
+
+			Ref: Person_friends.Person_id > Person.id
+			Ref: Person_friends.friends_id > Person.id
+			`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := Parse(strings.NewReader(tt.args.src))
+			assert.NoError(t, err)
+			var out bytes.Buffer
+			err = DumpDBML(&out, tables, tt.args.dialect, tt.args.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want+"\n", out.String())
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package md2sql
+
+type foreignKey struct {
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	Deferred   bool
+}
+
+// foreignKeys groups the link columns of the table into foreign key constraints.
+// Columns that refer different columns of the same table are merged into one composite key.
+func foreignKeys(t *Table) []*foreignKey {
+	var result []*foreignKey
+	for _, c := range t.Columns {
+		if c.LinkTable == "" || c.AssociativeEntity {
+			continue
+		}
+		var found *foreignKey
+		for _, fk := range result {
+			if fk.RefTable == c.LinkTable && !contains(fk.RefColumns, c.LinkColumn) {
+				found = fk
+				break
+			}
+		}
+		if found == nil {
+			found = &foreignKey{
+				Table:    t.Name,
+				RefTable: c.LinkTable,
+			}
+			result = append(result, found)
+		}
+		found.Columns = append(found.Columns, c.Name)
+		found.RefColumns = append(found.RefColumns, c.LinkColumn)
+	}
+	return result
+}
+
+// sortTables returns the tables in the order they can be created and their foreign keys.
+// Foreign keys that refer tables not created yet (cycles and self references) are marked as Deferred.
+// Those foreign keys should be added after all tables are created.
+//
+// Tables keep the source order as far as possible. If keepSourceOrder is true,
+// the source order is used as is and all forward references are deferred.
+func sortTables(tables []*Table, keepSourceOrder bool) ([]*Table, map[string][]*foreignKey) {
+	fks := make(map[string][]*foreignKey)
+	exists := make(map[string]bool)
+	for _, t := range tables {
+		fks[t.Name] = foreignKeys(t)
+		exists[t.Name] = true
+	}
+
+	var ordered []*Table
+	if keepSourceOrder {
+		ordered = tables
+	} else {
+		done := make(map[string]bool)
+		ready := func(t *Table) bool {
+			for _, fk := range fks[t.Name] {
+				if fk.RefTable == t.Name || !exists[fk.RefTable] {
+					continue
+				}
+				if !done[fk.RefTable] {
+					return false
+				}
+			}
+			return true
+		}
+		for len(ordered) < len(tables) {
+			// pick the first table that all dependencies are satisfied.
+			// if there is no such table, there is a cycle; break it at the first remaining table.
+			var next *Table
+			for _, t := range tables {
+				if done[t.Name] {
+					continue
+				}
+				if next == nil {
+					next = t
+				}
+				if ready(t) {
+					next = t
+					break
+				}
+			}
+			done[next.Name] = true
+			ordered = append(ordered, next)
+		}
+	}
+
+	created := make(map[string]bool)
+	for _, t := range ordered {
+		for _, fk := range fks[t.Name] {
+			fk.Deferred = !created[fk.RefTable] && exists[fk.RefTable]
+		}
+		created[t.Name] = true
+	}
+	return ordered, fks
+}
@@ -0,0 +1,479 @@
+package md2sql
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Dialect generates SQL of a database.
+//
+// Most dialects can be defined by BaseDialect. Embed *BaseDialect and override methods
+// if the database needs special handling. Register it by RegisterDialect to use it by name.
+type Dialect interface {
+	// String returns the display name like "PostgreSQL".
+	String() string
+
+	// TypeConversion converts the type in the Markdown into the SQL type.
+	TypeConversion(t string) string
+	// IsKnownType returns true if the type is a portable type or a native type of the dialect.
+	IsKnownType(t string) bool
+	// PrimaryKeySQLType returns the type of the primary key. Empty t means the key doesn't have a type.
+	PrimaryKeySQLType(t string, autoIncrement bool) string
+	// PrimaryKeyBaseType returns the type of columns that refer the primary key of type t.
+	// Unique columns also use it because they are keys of indexes.
+	PrimaryKeyBaseType(t string) string
+	// NullableType returns the type of nullable columns.
+	NullableType(t string) string
+	// Identity returns the definition of auto-increment keys of the identity strategy like "uuid".
+	// Empty strategy returns the default definition.
+	Identity(strategy string) (AutoIncrement, bool)
+
+	// QuoteIdentifier returns the quoted identifier if the dialect requires quoting.
+	QuoteIdentifier(name string) string
+	// IdentifierLimit returns the maximum length of identifiers.
+	// If inBytes is true, the length is counted in bytes, otherwise in characters. Zero means no limit.
+	IdentifierLimit() (length int, inBytes bool)
+
+	// EnableForeignKey returns the preamble that enables foreign key constraints.
+	EnableForeignKey(hasForeignKey bool) string
+	// CreateSequence returns the statement that creates the sequence for auto-increment keys.
+	CreateSequence(name string) string
+	// SequenceDefault returns the DEFAULT clause that gets the next value of the sequence.
+	SequenceDefault(name string) string
+	// TableOptions returns the clauses after the column list from the table annotations.
+	// keys are quoted primary key columns.
+	TableOptions(annotations map[string]string, keys []string) string
+	// BatchSeparator returns the string that is written after each statement.
+	BatchSeparator() string
+
+	// AddColumn returns the clause of ALTER TABLE that adds the column like "ADD COLUMN name TEXT NOT NULL".
+	AddColumn(definition string) string
+	// AlterColumn returns the clauses of ALTER TABLE that change the type or the nullability of the quoted column.
+	// Each clause is written as a separate ALTER TABLE statement.
+	AlterColumn(column string, change ColumnChange) []string
+	// DropPrimaryKey returns the clause of ALTER TABLE that drops the primary key of the quoted name.
+	// It returns empty string if the database needs the name to drop it but the name is empty.
+	DropPrimaryKey(name string) string
+	// DropForeignKey returns the clause of ALTER TABLE that drops the foreign key of the quoted name.
+	DropForeignKey(name string) string
+	// DropIndex returns the statement that drops the index of the table.
+	DropIndex(name, table string) string
+	// DropTableCascade returns the clause of DROP TABLE that also drops foreign keys referring the table
+	// like " CASCADE". Empty string means the database doesn't have it.
+	DropTableCascade() string
+	// ForeignKeyChecks returns the statements that disable and enable the checks of foreign keys
+	// like "SET FOREIGN_KEY_CHECKS = 0". Empty strings mean the database can't disable them.
+	ForeignKeyChecks() (disable, enable string)
+	// IfForeignKey returns the statement that runs the ALTER TABLE statement only if the foreign key exists
+	// (exists is true) or doesn't exist. Empty string means the database can't check it.
+	IfForeignKey(fk ForeignKeyRef, exists bool, statement string) string
+	// ConstraintNaming returns the names that the database gives to unnamed constraints.
+	// They are used to drop constraints. Empty templates mean the names can't be known.
+	ConstraintNaming() NamingConvention
+
+	// Capabilities returns the features of the dialect.
+	Capabilities() Capabilities
+}
+
+// ForeignKeyRef identifies the foreign key for Dialect.IfForeignKey. Names aren't quoted.
+// Name is empty if neither the script nor the database names the foreign key.
+type ForeignKeyRef struct {
+	Table    string
+	Columns  []string
+	RefTable string
+	Name     string
+}
+
+// Capabilities are features of the dialect. The zero value is standard SQL.
+type Capabilities struct {
+	// NoForeignKey means the database doesn't enforce foreign keys. They are written as comments.
+	NoForeignKey bool
+	// NoAlterForeignKey means the database can't add foreign keys to existing tables.
+	NoAlterForeignKey bool
+	// LazyForeignKeyCheck means the database doesn't check the referred table at CREATE TABLE.
+	// Such dialect can have foreign keys to tables that will be created later.
+	LazyForeignKeyCheck bool
+	// UnenforcedConstraint means primary keys and foreign keys are declared with NOT ENFORCED.
+	UnenforcedConstraint bool
+	// NoUniqueIndex means the database doesn't have unique indexes. They are written as comments.
+	NoUniqueIndex bool
+
+	// SequenceTrigger means auto-increment keys are implemented by a sequence and a trigger.
+	SequenceTrigger bool
+	// SequenceDefault means auto-increment keys are implemented by a sequence and the default value.
+	SequenceDefault bool
+	// BitReversedSequence means WithBitReversedSequence is available.
+	BitReversedSequence bool
+
+	// NoAlterColumn means the database can't change types and nullability of existing columns.
+	NoAlterColumn bool
+	// NoAlterPrimaryKey means the database can't change the primary key of existing tables.
+	NoAlterPrimaryKey bool
+	// NoAddRequiredColumn means the database can't add NOT NULL columns without default values to existing tables.
+	NoAddRequiredColumn bool
+	// NoCreateIfNotExists means the database doesn't have CREATE TABLE IF NOT EXISTS and CREATE SEQUENCE IF NOT EXISTS.
+	NoCreateIfNotExists bool
+	// NoIndexIfNotExists means the database doesn't have CREATE INDEX IF NOT EXISTS.
+	NoIndexIfNotExists bool
+	// NoDropIfExists means the database doesn't have DROP TABLE IF EXISTS and DROP SEQUENCE IF EXISTS.
+	NoDropIfExists bool
+	// NoDropTableWithIndex means indexes should be dropped before their tables.
+	NoDropTableWithIndex bool
+
+	// PrimaryKeyAfterColumns means the primary key is written after the column list.
+	PrimaryKeyAfterColumns bool
+	// InlineAutoIncrementKey means the auto-increment key is declared with PRIMARY KEY in the column definition
+	// like "INTEGER PRIMARY KEY AUTOINCREMENT". The modifier is dropped if the key is a part of a composite key.
+	InlineAutoIncrementKey bool
+	// StrictTables means tables can be STRICT ("!strict" annotation) that accept INT, INTEGER, REAL, TEXT, BLOB and ANY.
+	StrictTables bool
+	// OrderByKey means the primary key is written as the sorting key by TableOptions.
+	OrderByKey bool
+	// Interleave means dependent tables can be interleaved in their parent tables.
+	Interleave bool
+	// Dataset means table names can be qualified by WithDataset.
+	Dataset bool
+}
+
+// AutoIncrement defines auto-increment primary keys. Both the key column and the columns that refer it
+// are derived from this definition, so their types always match.
+type AutoIncrement struct {
+	// Type is the type of the key, which is also used by the columns that refer the key like "BIGINT UNSIGNED".
+	Type string
+	// Modifier is written after Type on the key column like "AUTO_INCREMENT".
+	Modifier string
+	// Pseudo is the pseudo type that is written on the key column instead of Type and Modifier like "SERIAL".
+	// It must be the shorthand of Type.
+	Pseudo string
+}
+
+// KeyType returns the type of the key column.
+func (a AutoIncrement) KeyType() string {
+	if a.Pseudo != "" {
+		return a.Pseudo
+	}
+	if a.Modifier != "" {
+		return a.Type + " " + a.Modifier
+	}
+	return a.Type
+}
+
+// ColumnChange is the change of a column passed to Dialect.AlterColumn.
+type ColumnChange struct {
+	// Type is the SQL type of the column. It is wrapped by Dialect.NullableType if Nullable is true.
+	Type string
+	// Nullable is the new nullability of the column.
+	Nullable bool
+	// TypeChanged means the type of the column is changed.
+	TypeChanged bool
+	// NullableChanged means the nullability of the column is changed.
+	NullableChanged bool
+}
+
+// Definition returns the type with the nullability like "TEXT NOT NULL".
+func (c ColumnChange) Definition() string {
+	if c.Nullable {
+		return c.Type
+	}
+	return c.Type + " NOT NULL"
+}
+
+// BaseDialect is a Dialect defined by its fields.
+type BaseDialect struct {
+	// Name is the display name.
+	Name string
+	// Types maps portable type names into SQL types. See typeAliases for the portable type names.
+	// "{args}" is replaced with arguments of the source type like "10,2" of "decimal(10,2)".
+	// If the source type doesn't have arguments, "({args})" is removed.
+	Types map[string]string
+	// NativeTypes are type names that are accepted without warnings.
+	NativeTypes []string
+	// KeyTypes overrides Types for primary keys, unique columns and foreign keys
+	// like the bounded NVARCHAR of SQL Server, whose NVARCHAR(MAX) can't be in indexes.
+	KeyTypes map[string]string
+	// AutoIncrement defines auto-increment primary keys and the type of the columns that refer them.
+	AutoIncrement AutoIncrement
+	// Identities are alternative definitions of auto-increment keys selected by the identity strategy.
+	Identities map[string]AutoIncrement
+
+	// Quote quotes identifiers. Identifiers are written as is if nil.
+	Quote func(name string) string
+	// MaxIdentifierLength is the maximum length of identifiers. Zero means no limit.
+	MaxIdentifierLength int
+	// IdentifierLengthInBytes means MaxIdentifierLength is counted in bytes.
+	IdentifierLengthInBytes bool
+
+	// ForeignKeyPreamble is written at the top if there are foreign keys.
+	ForeignKeyPreamble string
+	// SequenceFormat is the format of CREATE SEQUENCE statement. Default is "CREATE SEQUENCE %s".
+	SequenceFormat string
+	// SequenceDefaultFormat is the format of DEFAULT clause of the sequence. Default is " DEFAULT nextval('%s')".
+	SequenceDefaultFormat string
+	// NullableFormat is the format of nullable types like "Nullable(%s)". Default is "%s".
+	NullableFormat string
+	// TableOptionsFunc returns the clauses after the column list. See Dialect.TableOptions.
+	TableOptionsFunc func(annotations map[string]string, keys []string) string
+	// Separator is written after each statement.
+	Separator string
+
+	// AddColumnFormat is the format of the clause that adds a column. Default is "ADD COLUMN %s".
+	AddColumnFormat string
+	// AlterColumnFunc returns the clauses that change a column. See Dialect.AlterColumn.
+	// Default is "ALTER COLUMN c TYPE t" and "ALTER COLUMN c SET NOT NULL" ("DROP NOT NULL").
+	AlterColumnFunc func(column string, change ColumnChange) []string
+	// DropPrimaryKeyClause is the clause that drops the primary key without its name like "DROP PRIMARY KEY".
+	// "DROP CONSTRAINT name" is used if empty.
+	DropPrimaryKeyClause string
+	// DropForeignKeyFormat is the format of the clause that drops a foreign key. Default is "DROP CONSTRAINT %s".
+	DropForeignKeyFormat string
+	// DropIndexFormat is the format of DROP INDEX statement. %[1]s is the index and %[2]s is the table.
+	// Default is "DROP INDEX %[1]s".
+	DropIndexFormat string
+	// CascadeClause is written after DROP TABLE to drop foreign keys referring the table like " CASCADE".
+	CascadeClause string
+	// DisableForeignKeyChecks and EnableForeignKeyChecks are the statements that switch the checks of foreign keys.
+	DisableForeignKeyChecks string
+	EnableForeignKeyChecks  string
+	// IfForeignKeyFunc returns the statement that runs only if the foreign key exists or not. See Dialect.IfForeignKey.
+	IfForeignKeyFunc func(fk ForeignKeyRef, exists bool, statement string) string
+	// ConstraintNames are the names that the database gives to unnamed constraints like "{table}_pkey".
+	ConstraintNames NamingConvention
+
+	Flags Capabilities
+}
+
+func (d *BaseDialect) String() string {
+	return d.Name
+}
+
+func (d *BaseDialect) TypeConversion(t string) string {
+	name, args := splitType(t)
+	if sqlType, ok := d.Types[portableTypeName(name)]; ok {
+		return expandType(sqlType, args)
+	}
+	return strings.ToUpper(t)
+}
+
+func (d *BaseDialect) IsKnownType(t string) bool {
+	name, _ := splitType(t)
+	name = portableTypeName(name)
+	if _, ok := d.Types[name]; ok {
+		return true
+	}
+	for _, sqlType := range d.Types {
+		if native, _ := splitType(sqlType); strings.EqualFold(native, name) {
+			return true
+		}
+	}
+	for _, native := range d.NativeTypes {
+		if native == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *BaseDialect) PrimaryKeySQLType(t string, autoIncrement bool) string {
+	if t == "" {
+		if autoIncrement {
+			return d.AutoIncrement.KeyType()
+		}
+		return d.PrimaryKeyBaseType("")
+	}
+	return d.keyTypeConversion(t)
+}
+
+func (d *BaseDialect) PrimaryKeyBaseType(t string) string {
+	if t == "" {
+		return d.AutoIncrement.Type
+	}
+	return d.keyTypeConversion(t)
+}
+
+func (d *BaseDialect) keyTypeConversion(t string) string {
+	name, args := splitType(t)
+	if sqlType, ok := d.KeyTypes[portableTypeName(name)]; ok {
+		return expandType(sqlType, args)
+	}
+	return d.TypeConversion(t)
+}
+
+func (d *BaseDialect) Identity(strategy string) (AutoIncrement, bool) {
+	if strategy == "" {
+		return d.AutoIncrement, true
+	}
+	a, ok := d.Identities[strings.ToLower(strategy)]
+	return a, ok
+}
+
+func (d *BaseDialect) NullableType(t string) string {
+	if d.NullableFormat == "" {
+		return t
+	}
+	return fmt.Sprintf(d.NullableFormat, t)
+}
+
+func (d *BaseDialect) QuoteIdentifier(name string) string {
+	if d.Quote == nil {
+		return name
+	}
+	return d.Quote(name)
+}
+
+func (d *BaseDialect) IdentifierLimit() (length int, inBytes bool) {
+	return d.MaxIdentifierLength, d.IdentifierLengthInBytes
+}
+
+func (d *BaseDialect) EnableForeignKey(hasForeignKey bool) string {
+	if hasForeignKey {
+		return d.ForeignKeyPreamble
+	}
+	return ""
+}
+
+func (d *BaseDialect) CreateSequence(name string) string {
+	if d.SequenceFormat == "" {
+		return "CREATE SEQUENCE " + name
+	}
+	return fmt.Sprintf(d.SequenceFormat, name)
+}
+
+func (d *BaseDialect) SequenceDefault(name string) string {
+	if d.SequenceDefaultFormat == "" {
+		return " DEFAULT nextval('" + name + "')"
+	}
+	return fmt.Sprintf(d.SequenceDefaultFormat, name)
+}
+
+func (d *BaseDialect) TableOptions(annotations map[string]string, keys []string) string {
+	if d.TableOptionsFunc == nil {
+		return ""
+	}
+	return d.TableOptionsFunc(annotations, keys)
+}
+
+func (d *BaseDialect) BatchSeparator() string {
+	return d.Separator
+}
+
+func (d *BaseDialect) AddColumn(definition string) string {
+	if d.AddColumnFormat == "" {
+		return "ADD COLUMN " + definition
+	}
+	return fmt.Sprintf(d.AddColumnFormat, definition)
+}
+
+func (d *BaseDialect) AlterColumn(column string, change ColumnChange) []string {
+	if d.AlterColumnFunc != nil {
+		return d.AlterColumnFunc(column, change)
+	}
+	var result []string
+	if change.TypeChanged {
+		result = append(result, fmt.Sprintf("ALTER COLUMN %s TYPE %s", column, change.Type))
+	}
+	if change.NullableChanged && change.Nullable {
+		result = append(result, fmt.Sprintf("ALTER COLUMN %s DROP NOT NULL", column))
+	} else if change.NullableChanged {
+		result = append(result, fmt.Sprintf("ALTER COLUMN %s SET NOT NULL", column))
+	}
+	return result
+}
+
+func (d *BaseDialect) DropPrimaryKey(name string) string {
+	if d.DropPrimaryKeyClause != "" {
+		return d.DropPrimaryKeyClause
+	}
+	if name == "" {
+		return ""
+	}
+	return "DROP CONSTRAINT " + name
+}
+
+func (d *BaseDialect) DropForeignKey(name string) string {
+	if d.DropForeignKeyFormat == "" {
+		return "DROP CONSTRAINT " + name
+	}
+	return fmt.Sprintf(d.DropForeignKeyFormat, name)
+}
+
+func (d *BaseDialect) DropIndex(name, table string) string {
+	if d.DropIndexFormat == "" {
+		return "DROP INDEX " + name
+	}
+	return fmt.Sprintf(d.DropIndexFormat, name, table)
+}
+
+func (d *BaseDialect) DropTableCascade() string {
+	return d.CascadeClause
+}
+
+func (d *BaseDialect) ForeignKeyChecks() (disable, enable string) {
+	return d.DisableForeignKeyChecks, d.EnableForeignKeyChecks
+}
+
+func (d *BaseDialect) IfForeignKey(fk ForeignKeyRef, exists bool, statement string) string {
+	if d.IfForeignKeyFunc == nil {
+		return ""
+	}
+	return d.IfForeignKeyFunc(fk, exists, statement)
+}
+
+func (d *BaseDialect) ConstraintNaming() NamingConvention {
+	return d.ConstraintNames
+}
+
+func (d *BaseDialect) Capabilities() Capabilities {
+	return d.Flags
+}
+
+var (
+	dialectsMu   sync.RWMutex
+	dialects     = make(map[string]Dialect)
+	dialectNames []string
+)
+
+// RegisterDialect makes the dialect available by the names.
+// The first name is used in the list of DialectNames. Names are case-insensitive.
+// It panics if the name is already registered.
+func RegisterDialect(d Dialect, names ...string) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	if d == nil {
+		panic("md2sql: RegisterDialect dialect is nil")
+	}
+	if len(names) == 0 {
+		panic("md2sql: RegisterDialect needs names")
+	}
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if _, dup := dialects[name]; dup {
+			panic("md2sql: RegisterDialect called twice for " + name)
+		}
+		dialects[name] = d
+	}
+	dialectNames = append(dialectNames, strings.ToLower(names[0]))
+}
+
+// LookupDialect returns the dialect registered by the name.
+func LookupDialect(name string) (Dialect, bool) {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	d, ok := dialects[strings.ToLower(name)]
+	return d, ok
+}
+
+// DialectNames returns the primary names of the registered dialects in the registration order.
+func DialectNames() []string {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	return append([]string(nil), dialectNames...)
+}
+
+// ToDialect returns the dialect registered by the name. PostgreSQL is returned for unknown names.
+func ToDialect(src string) Dialect {
+	if d, ok := LookupDialect(src); ok {
+		return d
+	}
+	return PostgreSQL
+}
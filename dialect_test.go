@@ -0,0 +1,99 @@
+package md2sql
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// customDialect overrides quoting of BaseDialect like third-party dialects.
+type customDialect struct {
+	*BaseDialect
+}
+
+func (d customDialect) QuoteIdentifier(name string) string {
+	return `"` + name + `"`
+}
+
+var testDialect = customDialect{
+	BaseDialect: &BaseDialect{
+		Name: "TestDB",
+		Types: map[string]string{
+			"string":  "VARCHAR(255)",
+			"integer": "INT",
+		},
+		AutoIncrement: AutoIncrement{Type: "INT", Modifier: "AUTO_INCREMENT"},
+		Flags: Capabilities{
+			NoAlterForeignKey: true,
+		},
+	},
+}
+
+func init() {
+	RegisterDialect(testDialect, "testdb", "test-db")
+}
+
+func TestLookupDialect(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   Dialect
+		wantOK bool
+	}{
+		{name: "postgres", want: PostgreSQL, wantOK: true},
+		{name: "PG", want: PostgreSQL, wantOK: true},
+		{name: "mssql", want: SQLServer, wantOK: true},
+		{name: "bq", want: BigQuery, wantOK: true},
+		{name: "test-db", want: testDialect, wantOK: true},
+		{name: "db2", want: nil, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LookupDialect(tt.name)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestToDialect_Unknown(t *testing.T) {
+	assert.Equal(t, PostgreSQL, ToDialect("db2"))
+}
+
+func TestDialectNames(t *testing.T) {
+	names := DialectNames()
+	assert.Equal(t, []string{"postgres", "mysql", "sqlite", "sqlserver", "oracle", "oracle11g", "duckdb", "spanner", "clickhouse", "bigquery"}, names[:10])
+	assert.Contains(t, names, "testdb")
+	assert.NotContains(t, names, "test-db")
+}
+
+func TestRegisterDialect_Duplicated(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterDialect(testDialect, "postgres")
+	})
+}
+
+func TestSQL_CustomDialect(t *testing.T) {
+	tables, err := Parse(bytes.NewBufferString(TrimIndent(t, `
+	* table: User
+	  * @id
+	  * name: string
+	  * boss: *User.id?
+	`)))
+	assert.NoError(t, err)
+	var out bytes.Buffer
+	err = DumpSQL(&out, tables, ToDialect("testdb"))
+	assert.NoError(t, err)
+	assert.Equal(t, TrimIndent(t, `
+	CREATE TABLE "User"(
+		"id" INT AUTO_INCREMENT,
+		"name" VARCHAR(255) NOT NULL,
+		"boss" INT,
+		PRIMARY KEY("id")
+	);
+
+	-- WARNING: User(boss) doesn't refer User because TestDB can't add foreign keys in cycles
+
+	-- ALTER TABLE "User" ADD FOREIGN KEY("boss") REFERENCES "User"("id") (TestDB can't add foreign keys to existing tables);
+	`), out.String())
+}
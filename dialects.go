@@ -0,0 +1,544 @@
+package md2sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// built-in dialects
+var (
+	PostgreSQL Dialect = &BaseDialect{
+		Name: "PostgreSQL",
+		Types: map[string]string{
+			"string":      "TEXT",
+			"text":        "TEXT",
+			"varchar":     "VARCHAR({args})",
+			"char":        "CHAR({args})",
+			"smallint":    "SMALLINT",
+			"integer":     "INTEGER",
+			"bigint":      "BIGINT",
+			"float":       "REAL",
+			"double":      "DOUBLE PRECISION",
+			"decimal":     "NUMERIC({args})",
+			"bool":        "BOOLEAN",
+			"date":        "DATE",
+			"time":        "TIME({args})",
+			"timetz":      "TIME({args}) WITH TIME ZONE",
+			"timestamp":   "TIMESTAMP({args})",
+			"timestamptz": "TIMESTAMP({args}) WITH TIME ZONE",
+			"uuid":        "UUID",
+			"json":        "JSONB",
+			"binary":      "BYTEA",
+		},
+		NativeTypes:   []string{"int", "int2", "int4", "int8", "serial", "bigserial", "smallserial", "bytea", "jsonb", "citext", "inet", "cidr", "money", "interval", "xml"},
+		AutoIncrement: AutoIncrement{Type: "INTEGER", Pseudo: "SERIAL"},
+		Identities: map[string]AutoIncrement{
+			"serial":          {Type: "INTEGER", Pseudo: "SERIAL"},
+			"bigserial":       {Type: "BIGINT", Pseudo: "BIGSERIAL"},
+			"identity":        {Type: "BIGINT", Modifier: "GENERATED BY DEFAULT AS IDENTITY"},
+			"identity-always": {Type: "BIGINT", Modifier: "GENERATED ALWAYS AS IDENTITY"},
+			"uuid":            {Type: "UUID", Modifier: "DEFAULT gen_random_uuid()"},
+			"uuidv7":          {Type: "UUID", Modifier: "DEFAULT uuidv7()"},
+			"ulid":            {Type: "CHAR(26)", Modifier: "DEFAULT gen_ulid()"},
+		},
+		MaxIdentifierLength:     63,
+		IdentifierLengthInBytes: true,
+		CascadeClause:           " CASCADE",
+		IfForeignKeyFunc:        ifPostgreSQLForeignKey,
+		ConstraintNames: NamingConvention{
+			PrimaryKey: "{table}_pkey",
+			ForeignKey: "{table}_{columns}_fkey",
+		},
+	}
+	MySQL Dialect = &BaseDialect{
+		Name: "MySQL",
+		Types: map[string]string{
+			"string":      "TEXT",
+			"text":        "TEXT",
+			"varchar":     "VARCHAR({args})",
+			"char":        "CHAR({args})",
+			"smallint":    "SMALLINT",
+			"integer":     "INTEGER",
+			"bigint":      "BIGINT",
+			"float":       "FLOAT",
+			"double":      "DOUBLE",
+			"decimal":     "DECIMAL({args})",
+			"bool":        "BOOLEAN",
+			"date":        "DATE",
+			"time":        "TIME({args})",
+			"timetz":      "TIME({args})",
+			"timestamp":   "DATETIME({args})",
+			"timestamptz": "TIMESTAMP({args})",
+			"uuid":        "CHAR(36)",
+			"json":        "JSON",
+			"binary":      "BLOB",
+		},
+		NativeTypes:          []string{"int", "tinyint", "mediumint", "tinytext", "mediumtext", "longtext", "tinyblob", "mediumblob", "longblob", "varbinary", "enum", "set", "year", "bit"},
+		AutoIncrement:        AutoIncrement{Type: "BIGINT UNSIGNED", Pseudo: "SERIAL"},
+		MaxIdentifierLength:  64,
+		AlterColumnFunc:      modifyColumn,
+		DropPrimaryKeyClause: "DROP PRIMARY KEY",
+		DropForeignKeyFormat: "DROP FOREIGN KEY %s",
+		DropIndexFormat:      "DROP INDEX %[1]s ON %[2]s",
+		// tables in cycles can't be dropped one by one
+		DisableForeignKeyChecks: "SET FOREIGN_KEY_CHECKS = 0",
+		EnableForeignKeyChecks:  "SET FOREIGN_KEY_CHECKS = 1",
+		IfForeignKeyFunc:        ifMySQLForeignKey,
+		Flags: Capabilities{
+			NoIndexIfNotExists: true,
+		},
+	}
+	SQLite Dialect = &BaseDialect{
+		Name: "SQLite",
+		Types: map[string]string{
+			"string":      "TEXT",
+			"text":        "TEXT",
+			"varchar":     "TEXT",
+			"char":        "TEXT",
+			"smallint":    "INTEGER",
+			"integer":     "INTEGER",
+			"bigint":      "INTEGER",
+			"float":       "REAL",
+			"double":      "REAL",
+			"decimal":     "NUMERIC",
+			"bool":        "INTEGER",
+			"date":        "TEXT",
+			"time":        "TEXT",
+			"timetz":      "TEXT",
+			"timestamp":   "TEXT",
+			"timestamptz": "TEXT",
+			"uuid":        "TEXT",
+			"json":        "TEXT",
+			"binary":      "BLOB",
+		},
+		NativeTypes:        []string{"int", "any"},
+		AutoIncrement:      AutoIncrement{Type: "INTEGER", Modifier: "AUTOINCREMENT"},
+		ForeignKeyPreamble: "PRAGMA foreign_keys = ON;\n\n",
+		TableOptionsFunc:   sqliteTableOptions,
+		Flags: Capabilities{
+			NoAlterForeignKey:      true,
+			NoAlterColumn:          true,
+			NoAlterPrimaryKey:      true,
+			NoAddRequiredColumn:    true,
+			LazyForeignKeyCheck:    true,
+			InlineAutoIncrementKey: true,
+			StrictTables:           true,
+		},
+	}
+	SQLServer Dialect = &BaseDialect{
+		Name: "SQLServer",
+		Types: map[string]string{
+			"string":      "NVARCHAR(MAX)",
+			"text":        "NVARCHAR(MAX)",
+			"varchar":     "NVARCHAR({args})",
+			"char":        "NCHAR({args})",
+			"smallint":    "SMALLINT",
+			"integer":     "INTEGER",
+			"bigint":      "BIGINT",
+			"float":       "REAL",
+			"double":      "FLOAT",
+			"decimal":     "DECIMAL({args})",
+			"bool":        "BIT",
+			"date":        "DATE",
+			"time":        "TIME({args})",
+			"timetz":      "TIME({args})",
+			"timestamp":   "DATETIME2({args})",
+			"timestamptz": "DATETIMEOFFSET({args})",
+			"uuid":        "UNIQUEIDENTIFIER",
+			"json":        "NVARCHAR(MAX)",
+			"binary":      "VARBINARY(MAX)",
+		},
+		NativeTypes: []string{"int", "tinyint", "money", "smallmoney", "datetime", "smalldatetime", "ntext", "image", "xml", "rowversion", "sql_variant"},
+		// 450 characters fit in the 900 bytes of index keys
+		KeyTypes: map[string]string{
+			"string": "NVARCHAR(450)",
+			"text":   "NVARCHAR(450)",
+			"binary": "VARBINARY(900)",
+		},
+		AutoIncrement:       AutoIncrement{Type: "INTEGER", Modifier: "IDENTITY(1,1)"},
+		Quote:               quoteSQLServer,
+		MaxIdentifierLength: 128,
+		Separator:           "\nGO",
+		AddColumnFormat:     "ADD %s",
+		AlterColumnFunc:     alterColumnDefinition,
+		DropIndexFormat:     "DROP INDEX %[1]s ON %[2]s",
+		IfForeignKeyFunc:    ifSQLServerForeignKey,
+		Flags: Capabilities{
+			NoCreateIfNotExists: true,
+			NoIndexIfNotExists:  true,
+		},
+	}
+	Oracle Dialect = &BaseDialect{
+		Name:                    "Oracle",
+		Types:                   oracleTypes,
+		NativeTypes:             oracleNativeTypes,
+		AutoIncrement:           AutoIncrement{Type: "NUMBER(19)", Modifier: "GENERATED BY DEFAULT AS IDENTITY"},
+		Quote:                   quoteOracle,
+		MaxIdentifierLength:     128,
+		IdentifierLengthInBytes: true,
+		AddColumnFormat:         "ADD %s",
+		AlterColumnFunc:         modifyOracleColumn,
+		CascadeClause:           " CASCADE CONSTRAINTS",
+		Flags: Capabilities{
+			NoCreateIfNotExists: true,
+			NoIndexIfNotExists:  true,
+			NoDropIfExists:      true,
+		},
+	}
+	Oracle11g Dialect = &BaseDialect{
+		Name:                    "Oracle11g",
+		Types:                   oracleTypes,
+		NativeTypes:             oracleNativeTypes,
+		AutoIncrement:           AutoIncrement{Type: "NUMBER(19)"},
+		Quote:                   quoteOracle,
+		MaxIdentifierLength:     30,
+		IdentifierLengthInBytes: true,
+		AddColumnFormat:         "ADD %s",
+		AlterColumnFunc:         modifyOracleColumn,
+		CascadeClause:           " CASCADE CONSTRAINTS",
+		Flags: Capabilities{
+			SequenceTrigger:     true,
+			NoCreateIfNotExists: true,
+			NoIndexIfNotExists:  true,
+			NoDropIfExists:      true,
+		},
+	}
+	DuckDB Dialect = &BaseDialect{
+		Name: "DuckDB",
+		Types: map[string]string{
+			"string":      "VARCHAR",
+			"text":        "VARCHAR",
+			"varchar":     "VARCHAR",
+			"char":        "VARCHAR",
+			"smallint":    "SMALLINT",
+			"integer":     "INTEGER",
+			"bigint":      "BIGINT",
+			"float":       "FLOAT",
+			"double":      "DOUBLE",
+			"decimal":     "DECIMAL({args})",
+			"bool":        "BOOLEAN",
+			"date":        "DATE",
+			"time":        "TIME",
+			"timetz":      "TIMETZ",
+			"timestamp":   "TIMESTAMP",
+			"timestamptz": "TIMESTAMPTZ",
+			"uuid":        "UUID",
+			"json":        "JSON",
+			"binary":      "BLOB",
+		},
+		NativeTypes:   []string{"int", "tinyint", "hugeint", "uhugeint", "utinyint", "usmallint", "uinteger", "ubigint", "interval", "bit", "struct", "map", "list", "union", "enum"},
+		AutoIncrement: AutoIncrement{Type: "BIGINT"},
+		Flags: Capabilities{
+			NoAlterForeignKey: true,
+			NoAlterPrimaryKey: true,
+			SequenceDefault:   true,
+		},
+	}
+	Spanner Dialect = &BaseDialect{
+		Name: "Spanner",
+		Types: map[string]string{
+			"string":      "STRING(MAX)",
+			"text":        "STRING(MAX)",
+			"varchar":     "STRING({args})",
+			"char":        "STRING({args})",
+			"smallint":    "INT64",
+			"integer":     "INT64",
+			"bigint":      "INT64",
+			"float":       "FLOAT32",
+			"double":      "FLOAT64",
+			"decimal":     "NUMERIC",
+			"bool":        "BOOL",
+			"date":        "DATE",
+			"time":        "STRING(MAX)",
+			"timetz":      "STRING(MAX)",
+			"timestamp":   "TIMESTAMP",
+			"timestamptz": "TIMESTAMP",
+			"uuid":        "STRING(36)",
+			"json":        "JSON",
+			"binary":      "BYTES(MAX)",
+		},
+		NativeTypes:           []string{"int64", "float32", "float64", "string", "bytes", "array"},
+		AutoIncrement:         AutoIncrement{Type: "STRING(36)", Modifier: "DEFAULT (GENERATE_UUID())"},
+		MaxIdentifierLength:   128,
+		SequenceFormat:        "CREATE SEQUENCE %s OPTIONS (sequence_kind = 'bit_reversed_positive')",
+		SequenceDefaultFormat: " DEFAULT (GET_NEXT_SEQUENCE_VALUE(SEQUENCE %s))",
+		AlterColumnFunc:       alterColumnDefinition,
+		Flags: Capabilities{
+			NoAlterPrimaryKey:      true,
+			BitReversedSequence:    true,
+			PrimaryKeyAfterColumns: true,
+			Interleave:             true,
+			NoDropTableWithIndex:   true,
+		},
+	}
+	ClickHouse Dialect = &BaseDialect{
+		Name: "ClickHouse",
+		Types: map[string]string{
+			"string":      "String",
+			"text":        "String",
+			"varchar":     "String",
+			"char":        "FixedString({args})",
+			"smallint":    "Int16",
+			"integer":     "Int32",
+			"bigint":      "Int64",
+			"float":       "Float32",
+			"double":      "Float64",
+			"decimal":     "Decimal({args})",
+			"bool":        "Bool",
+			"date":        "Date",
+			"time":        "String",
+			"timetz":      "String",
+			"timestamp":   "DateTime",
+			"timestamptz": "DateTime",
+			"uuid":        "UUID",
+			"json":        "JSON",
+			"binary":      "String",
+		},
+		NativeTypes:      []string{"int8", "int128", "int256", "uint8", "uint16", "uint32", "uint64", "uint128", "uint256", "datetime64", "date32", "lowcardinality", "enum8", "enum16", "array", "map", "tuple", "ipv4", "ipv6"},
+		AutoIncrement:    AutoIncrement{Type: "UUID", Modifier: "DEFAULT generateUUIDv4()"},
+		NullableFormat:   "Nullable(%s)",
+		TableOptionsFunc: clickHouseTableOptions,
+		AlterColumnFunc:  modifyColumn,
+		Flags: Capabilities{
+			NoForeignKey:      true,
+			NoUniqueIndex:     true,
+			NoAlterPrimaryKey: true,
+			OrderByKey:        true,
+		},
+	}
+	BigQuery Dialect = &BaseDialect{
+		Name: "BigQuery",
+		Types: map[string]string{
+			"string":      "STRING",
+			"text":        "STRING",
+			"varchar":     "STRING({args})",
+			"char":        "STRING({args})",
+			"smallint":    "INT64",
+			"integer":     "INT64",
+			"bigint":      "INT64",
+			"float":       "FLOAT64",
+			"double":      "FLOAT64",
+			"decimal":     "NUMERIC({args})",
+			"bool":        "BOOL",
+			"date":        "DATE",
+			"time":        "TIME",
+			"timetz":      "TIME",
+			"timestamp":   "DATETIME",
+			"timestamptz": "TIMESTAMP",
+			"uuid":        "STRING",
+			"json":        "JSON",
+			"binary":      "BYTES",
+		},
+		NativeTypes:          []string{"int64", "float64", "bignumeric", "bytes", "interval", "geography", "array", "struct", "range"},
+		AutoIncrement:        AutoIncrement{Type: "STRING", Modifier: "DEFAULT GENERATE_UUID()"},
+		TableOptionsFunc:     bigQueryTableOptions,
+		AlterColumnFunc:      alterBigQueryColumn,
+		DropPrimaryKeyClause: "DROP PRIMARY KEY",
+		Flags: Capabilities{
+			NoUniqueIndex:        true,
+			NoAddRequiredColumn:  true,
+			UnenforcedConstraint: true,
+			Dataset:              true,
+		},
+	}
+)
+
+func init() {
+	RegisterDialect(PostgreSQL, "postgres", "postgresql", "pg")
+	RegisterDialect(MySQL, "mysql", "maria", "mariadb")
+	RegisterDialect(SQLite, "sqlite")
+	RegisterDialect(SQLServer, "sqlserver", "mssql", "tsql")
+	RegisterDialect(Oracle, "oracle", "oracle12c")
+	RegisterDialect(Oracle11g, "oracle11g", "oracle11")
+	RegisterDialect(DuckDB, "duckdb")
+	RegisterDialect(Spanner, "spanner")
+	RegisterDialect(ClickHouse, "clickhouse")
+	RegisterDialect(BigQuery, "bigquery", "bq")
+}
+
+// oracleTypes is the type mapping shared by Oracle and Oracle11g, which only differ in auto-increment keys and identifier length.
+var oracleTypes = map[string]string{
+	"string":      "VARCHAR2(4000)",
+	"text":        "CLOB",
+	"varchar":     "VARCHAR2({args})",
+	"char":        "CHAR({args})",
+	"smallint":    "NUMBER(5)",
+	"integer":     "NUMBER(10)",
+	"bigint":      "NUMBER(19)",
+	"float":       "BINARY_FLOAT",
+	"double":      "BINARY_DOUBLE",
+	"decimal":     "NUMBER({args})",
+	"bool":        "NUMBER(1)",
+	"date":        "DATE",
+	"time":        "INTERVAL DAY TO SECOND",
+	"timetz":      "INTERVAL DAY TO SECOND",
+	"timestamp":   "TIMESTAMP({args})",
+	"timestamptz": "TIMESTAMP({args}) WITH TIME ZONE",
+	"uuid":        "RAW(16)",
+	"json":        "CLOB",
+	"binary":      "BLOB",
+}
+
+// oracleNativeTypes is the native types shared by Oracle and Oracle11g.
+var oracleNativeTypes = []string{"number", "nvarchar2", "nchar", "nclob", "raw", "long", "rowid", "xmltype"}
+
+// reserved words of Oracle that are likely to be used as table or column names
+var oracleReservedWords = map[string]bool{
+	"ACCESS": true, "AUDIT": true, "COLUMN": true, "COMMENT": true, "DATE": true, "FILE": true,
+	"GROUP": true, "INDEX": true, "LEVEL": true, "MODE": true, "NUMBER": true, "ORDER": true,
+	"RESOURCE": true, "ROW": true, "ROWS": true, "SESSION": true, "SIZE": true, "START": true,
+	"TABLE": true, "UID": true, "USER": true, "VALUES": true, "VIEW": true,
+}
+
+func quoteOracle(name string) string {
+	// Oracle folds unquoted identifiers into upper case. Reserved words need quotes.
+	name = strings.ToUpper(name)
+	if oracleReservedWords[name] {
+		return `"` + name + `"`
+	}
+	return name
+}
+
+func quoteSQLServer(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+// sqlString returns the string literal of SQL.
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// ifPostgreSQLForeignKey checks the foreign key by its name in DO block. The names that PostgreSQL gives are known.
+func ifPostgreSQLForeignKey(fk ForeignKeyRef, exists bool, statement string) string {
+	if fk.Name == "" {
+		return ""
+	}
+	not := "NOT "
+	if exists {
+		not = ""
+	}
+	// unquoted names are folded into lower case
+	return fmt.Sprintf("DO $$\nBEGIN\n\tIF %sEXISTS (SELECT * FROM pg_constraint WHERE conrelid = %s::regclass AND conname = %s) THEN\n\t\t%s;\n\tEND IF;\nEND\n$$",
+		not, sqlString(fk.Table), sqlString(strings.ToLower(fk.Name)), statement)
+}
+
+// ifMySQLForeignKey checks the foreign key by the first column in information_schema and runs the statement by PREPARE
+// because MySQL doesn't have IF statement outside of stored programs.
+func ifMySQLForeignKey(fk ForeignKeyRef, exists bool, statement string) string {
+	then, otherwise := "'DO 0'", sqlString(statement)
+	if exists {
+		then, otherwise = otherwise, then
+	}
+	return fmt.Sprintf("SET @md2sql = IF(EXISTS(SELECT * FROM information_schema.key_column_usage WHERE table_schema = DATABASE() AND table_name = %s AND column_name = %s AND referenced_table_name = %s), %s, %s);\nPREPARE md2sql FROM @md2sql;\nEXECUTE md2sql;\nDEALLOCATE PREPARE md2sql",
+		sqlString(fk.Table), sqlString(fk.Columns[0]), sqlString(fk.RefTable), then, otherwise)
+}
+
+// ifSQLServerForeignKey checks the foreign key by the first column in sys.foreign_key_columns.
+func ifSQLServerForeignKey(fk ForeignKeyRef, exists bool, statement string) string {
+	not := "NOT "
+	if exists {
+		not = ""
+	}
+	return fmt.Sprintf("IF %sEXISTS (SELECT * FROM sys.foreign_key_columns WHERE parent_object_id = OBJECT_ID(N%s) AND referenced_object_id = OBJECT_ID(N%s) AND COL_NAME(parent_object_id, parent_column_id) = N%s)\n\t%s",
+		not, sqlString(quoteSQLServer(fk.Table)), sqlString(quoteSQLServer(fk.RefTable)), sqlString(fk.Columns[0]), statement)
+}
+
+// modifyColumn changes the column by its definition like "MODIFY COLUMN c TEXT NOT NULL" (MySQL, ClickHouse).
+func modifyColumn(column string, change ColumnChange) []string {
+	return []string{"MODIFY COLUMN " + column + " " + change.Definition()}
+}
+
+// alterColumnDefinition changes the column by its definition like "ALTER COLUMN c TEXT NOT NULL" (SQL Server, Spanner).
+func alterColumnDefinition(column string, change ColumnChange) []string {
+	return []string{"ALTER COLUMN " + column + " " + change.Definition()}
+}
+
+// modifyOracleColumn changes the type and the nullability separately
+// because Oracle rejects NOT NULL of the column that is already NOT NULL.
+func modifyOracleColumn(column string, change ColumnChange) []string {
+	var result []string
+	if change.TypeChanged {
+		result = append(result, "MODIFY "+column+" "+change.Type)
+	}
+	if change.NullableChanged && change.Nullable {
+		result = append(result, "MODIFY "+column+" NULL")
+	} else if change.NullableChanged {
+		result = append(result, "MODIFY "+column+" NOT NULL")
+	}
+	return result
+}
+
+// alterBigQueryColumn changes the type and drops NOT NULL. BigQuery can't add NOT NULL to existing columns.
+func alterBigQueryColumn(column string, change ColumnChange) []string {
+	var result []string
+	if change.TypeChanged {
+		result = append(result, "ALTER COLUMN "+column+" SET DATA TYPE "+change.Type)
+	}
+	if change.NullableChanged && change.Nullable {
+		result = append(result, "ALTER COLUMN "+column+" DROP NOT NULL")
+	}
+	return result
+}
+
+// sqliteTableOptions returns STRICT and WITHOUT ROWID options.
+func sqliteTableOptions(annotations map[string]string, keys []string) string {
+	var options []string
+	if annotationFlag(annotations, "strict") {
+		options = append(options, "STRICT")
+	}
+	if annotationFlag(annotations, "without_rowid") {
+		options = append(options, "WITHOUT ROWID")
+	}
+	if len(options) == 0 {
+		return ""
+	}
+	return " " + strings.Join(options, ", ")
+}
+
+// sqliteStrictTypes are the types that STRICT tables accept.
+var sqliteStrictTypes = map[string]bool{
+	"INT": true, "INTEGER": true, "REAL": true, "TEXT": true, "BLOB": true, "ANY": true,
+}
+
+// strictType returns ANY for types that STRICT tables don't accept like NUMERIC.
+func strictType(t string) string {
+	if sqliteStrictTypes[strings.ToUpper(t)] {
+		return t
+	}
+	return "ANY"
+}
+
+// clickHouseTableOptions returns ENGINE, ORDER BY and PARTITION BY clauses.
+// The primary key is used as the sorting key unless "order_by" annotation is specified.
+func clickHouseTableOptions(annotations map[string]string, keys []string) string {
+	engine := annotations["engine"]
+	if engine == "" {
+		engine = "MergeTree"
+	}
+	if !strings.HasSuffix(engine, ")") {
+		engine += "()"
+	}
+	orderBy := annotations["order_by"]
+	if orderBy == "" && len(keys) > 0 {
+		orderBy = "(" + strings.Join(keys, ", ") + ")"
+	} else if orderBy == "" {
+		orderBy = "tuple()"
+	}
+	result := "\nENGINE = " + engine + "\nORDER BY " + orderBy
+	if partitionBy := annotations["partition_by"]; partitionBy != "" {
+		result += "\nPARTITION BY " + partitionBy
+	}
+	return result
+}
+
+// bigQueryTableOptions returns PARTITION BY and CLUSTER BY clauses.
+func bigQueryTableOptions(annotations map[string]string, keys []string) string {
+	var result string
+	if partitionBy := annotations["partition_by"]; partitionBy != "" {
+		result += "\nPARTITION BY " + partitionBy
+	}
+	if clusterBy := annotations["cluster_by"]; clusterBy != "" {
+		result += "\nCLUSTER BY " + clusterBy
+	}
+	return result
+}
@@ -0,0 +1,506 @@
+package md2sql
+
+import (
+	"io"
+	"strings"
+)
+
+// SchemaDiff is the difference between two versions of the tables.
+type SchemaDiff struct {
+	// AddedTables are the tables that exist only in the new version.
+	AddedTables []*Table
+	// DroppedTables are the tables that exist only in the old version.
+	DroppedTables []*Table
+	// ChangedTables are the tables that exist in both versions and have differences.
+	ChangedTables []*TableDiff
+
+	from []*Table
+	to   []*Table
+}
+
+// TableDiff is the difference of the table that exists in both versions.
+type TableDiff struct {
+	From *Table
+	To   *Table
+
+	AddedColumns   []*Column
+	DroppedColumns []*Column
+	ChangedColumns []*ColumnDiff
+	// PrimaryKeyChanged means the columns of the primary key are changed.
+	PrimaryKeyChanged bool
+	// AddedIndexes and DroppedIndexes are the columns whose unique indexes are created or dropped.
+	AddedIndexes   []*Column
+	DroppedIndexes []*Column
+	// AddedAssociations and DroppedAssociations are the associative entity columns like "tags: *Tag.id[]"
+	// whose tables are created or dropped.
+	AddedAssociations   []*Column
+	DroppedAssociations []*Column
+
+	addedForeignKeys   []*foreignKey
+	droppedForeignKeys []*foreignKey
+}
+
+// ColumnDiff is the change of the column that exists in both versions.
+type ColumnDiff struct {
+	From *Column
+	To   *Column
+}
+
+// TypeChanged returns true if the type, auto-increment or identity strategy of the column is changed.
+func (c *ColumnDiff) TypeChanged() bool {
+	return normalizeType(c.From.Type) != normalizeType(c.To.Type) ||
+		c.From.AutoIncrement != c.To.AutoIncrement ||
+		!strings.EqualFold(c.From.Identity, c.To.Identity)
+}
+
+// NullableChanged returns true if the nullability of the column is changed.
+func (c *ColumnDiff) NullableChanged() bool {
+	return c.From.Nullable != c.To.Nullable
+}
+
+// IsEmpty returns true if there are no differences.
+func (d *SchemaDiff) IsEmpty() bool {
+	return len(d.AddedTables) == 0 && len(d.DroppedTables) == 0 && len(d.ChangedTables) == 0
+}
+
+func (d *TableDiff) isEmpty() bool {
+	return len(d.AddedColumns) == 0 && len(d.DroppedColumns) == 0 && len(d.ChangedColumns) == 0 &&
+		!d.PrimaryKeyChanged && len(d.AddedIndexes) == 0 && len(d.DroppedIndexes) == 0 &&
+		len(d.AddedAssociations) == 0 && len(d.DroppedAssociations) == 0 &&
+		len(d.addedForeignKeys) == 0 && len(d.droppedForeignKeys) == 0
+}
+
+// normalizeType returns the portable type name with arguments like "decimal(10,2)" to compare types.
+func normalizeType(t string) string {
+	name, args := splitType(t)
+	if args == "" {
+		return portableTypeName(name)
+	}
+	return portableTypeName(name) + "(" + args + ")"
+}
+
+// Diff compares the old version of the tables with the new version.
+// Tables and columns are matched by their names, so renaming is treated as dropping and adding.
+func Diff(from, to []*Table) (*SchemaDiff, error) {
+	if _, err := fixRelations(from); err != nil {
+		return nil, err
+	}
+	if _, err := fixRelations(to); err != nil {
+		return nil, err
+	}
+	result := &SchemaDiff{from: from, to: to}
+	fromTables := make(map[string]*Table)
+	for _, t := range from {
+		fromTables[t.Name] = t
+	}
+	// tables whose primary keys are changed. Foreign keys and associative entities that refer them are recreated.
+	rekeyed := make(map[string]bool)
+	for _, t := range to {
+		if f, ok := fromTables[t.Name]; ok && keyChanged(f, t) {
+			rekeyed[t.Name] = true
+		}
+	}
+	toTables := make(map[string]*Table)
+	for _, t := range to {
+		toTables[t.Name] = t
+		if f, ok := fromTables[t.Name]; !ok {
+			result.AddedTables = append(result.AddedTables, t)
+		} else if td := diffTable(f, t, rekeyed); !td.isEmpty() {
+			result.ChangedTables = append(result.ChangedTables, td)
+		}
+	}
+	for _, t := range from {
+		if _, ok := toTables[t.Name]; !ok {
+			result.DroppedTables = append(result.DroppedTables, t)
+		}
+	}
+	return result, nil
+}
+
+// keyChanged returns true if the columns or the types of the primary key are changed.
+func keyChanged(from, to *Table) bool {
+	fromPKs := primaryKeys([]*Table{from})[from.Name]
+	toPKs := primaryKeys([]*Table{to})[to.Name]
+	if !equalStrings(fromPKs, toPKs) {
+		return true
+	}
+	for _, f := range from.Columns {
+		for _, c := range to.Columns {
+			if f.PrimaryKey && c.Name == f.Name && (&ColumnDiff{From: f, To: c}).TypeChanged() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func diffTable(from, to *Table, rekeyed map[string]bool) *TableDiff {
+	result := &TableDiff{From: from, To: to}
+	fromColumns := make(map[string]*Column)
+	for _, c := range from.Columns {
+		fromColumns[c.Name] = c
+	}
+	toColumns := make(map[string]*Column)
+	for _, c := range to.Columns {
+		toColumns[c.Name] = c
+	}
+	sameLink := func(a, b *Column) bool {
+		return a.AssociativeEntity == b.AssociativeEntity && a.LinkTable == b.LinkTable && a.LinkColumn == b.LinkColumn
+	}
+
+	for _, c := range to.Columns {
+		f, ok := fromColumns[c.Name]
+		if c.AssociativeEntity {
+			if !ok || !sameLink(f, c) {
+				result.AddedAssociations = append(result.AddedAssociations, c)
+			}
+			continue
+		}
+		if !ok || f.AssociativeEntity {
+			result.AddedColumns = append(result.AddedColumns, c)
+		} else if cd := (&ColumnDiff{From: f, To: c}); cd.TypeChanged() || cd.NullableChanged() {
+			result.ChangedColumns = append(result.ChangedColumns, cd)
+		}
+		if c.Index && !(ok && f.Index && !f.AssociativeEntity) {
+			result.AddedIndexes = append(result.AddedIndexes, c)
+		}
+	}
+	for _, f := range from.Columns {
+		c, ok := toColumns[f.Name]
+		if f.AssociativeEntity {
+			if !ok || !sameLink(f, c) {
+				result.DroppedAssociations = append(result.DroppedAssociations, f)
+			}
+			continue
+		}
+		if !ok || c.AssociativeEntity {
+			result.DroppedColumns = append(result.DroppedColumns, f)
+		}
+		if f.Index && !(ok && c.Index && !c.AssociativeEntity) {
+			result.DroppedIndexes = append(result.DroppedIndexes, f)
+		}
+	}
+
+	fromPKs := primaryKeys([]*Table{from})[from.Name]
+	toPKs := primaryKeys([]*Table{to})[to.Name]
+	result.PrimaryKeyChanged = !equalStrings(fromPKs, toPKs)
+
+	// tables of associative entities have the primary keys of both tables
+	for _, c := range to.Columns {
+		if f, ok := fromColumns[c.Name]; ok && c.AssociativeEntity && sameLink(f, c) && (rekeyed[to.Name] || rekeyed[c.LinkTable]) {
+			result.DroppedAssociations = append(result.DroppedAssociations, f)
+			result.AddedAssociations = append(result.AddedAssociations, c)
+		}
+	}
+
+	// foreign keys are recreated if their columns or the referred keys are changed
+	changed := make(map[string]bool)
+	for _, cd := range result.ChangedColumns {
+		if cd.TypeChanged() {
+			changed[cd.To.Name] = true
+		}
+	}
+	recreated := func(fk *foreignKey) bool {
+		if rekeyed[fk.RefTable] {
+			return true
+		}
+		for _, c := range fk.Columns {
+			if changed[c] {
+				return true
+			}
+		}
+		return false
+	}
+	fromFKs := foreignKeys(from)
+	toFKs := foreignKeys(to)
+	for _, fk := range toFKs {
+		if !containsForeignKey(fromFKs, fk) || recreated(fk) {
+			result.addedForeignKeys = append(result.addedForeignKeys, fk)
+		}
+	}
+	for _, fk := range fromFKs {
+		if !containsForeignKey(toFKs, fk) || recreated(fk) {
+			result.droppedForeignKeys = append(result.droppedForeignKeys, fk)
+		}
+	}
+	return result
+}
+
+func containsForeignKey(fks []*foreignKey, fk *foreignKey) bool {
+	for _, f := range fks {
+		if f.RefTable == fk.RefTable && equalStrings(f.Columns, fk.Columns) && equalStrings(f.RefColumns, fk.RefColumns) {
+			return true
+		}
+	}
+	return false
+}
+
+// DumpMigration writes ALTER TABLE statements that migrate the database from the old version of the tables
+// to the new version. Statements that the dialect doesn't support are written as comments.
+func DumpMigration(w io.Writer, from, to []*Table, d Dialect, opts ...Option) error {
+	diff, err := Diff(from, to)
+	if err != nil {
+		return err
+	}
+	return diff.DumpSQL(w, d, opts...)
+}
+
+// DumpSQL writes ALTER TABLE statements that apply the difference. See DumpMigration.
+func (diff *SchemaDiff) DumpSQL(w io.Writer, d Dialect, opts ...Option) error {
+	o := newOption(opts)
+	md := o.dialect(d)
+	s := &sqlWriter{w: w, d: md, o: o}
+
+	// drop foreign keys first not to block dropping and changing tables and columns
+	for _, td := range diff.ChangedTables {
+		for _, fk := range td.droppedForeignKeys {
+			s.dropForeignKey(fk)
+		}
+	}
+	_, droppedFKs := sortTables(diff.DroppedTables, false)
+	for _, t := range diff.DroppedTables {
+		for _, fk := range droppedFKs[t.Name] {
+			if fk.Deferred {
+				s.dropForeignKey(fk)
+			}
+		}
+	}
+	for _, td := range diff.ChangedTables {
+		for _, c := range td.DroppedIndexes {
+			s.dropIndex(td.From.Name, c.Name)
+		}
+	}
+
+	// drop tables
+	for _, td := range diff.ChangedTables {
+		for _, c := range td.DroppedAssociations {
+			s.dropAssociativeTable(td.From, c)
+		}
+	}
+	dropped, _ := sortTables(diff.DroppedTables, false)
+	for _, t := range dropped {
+		for _, c := range t.Columns {
+			if c.LinkTable != "" && c.AssociativeEntity {
+				s.dropAssociativeTable(t, c)
+			}
+		}
+	}
+	for i := len(dropped) - 1; i >= 0; i-- {
+		s.dropTable(dropped[i])
+	}
+
+	// create tables
+	tablePKs := primaryKeys(diff.to)
+	added, fks := sortTables(diff.AddedTables, o.sourceOrder)
+	var deferred []*foreignKey
+	for _, t := range added {
+		deferred = append(deferred, s.createTable(t, fks[t.Name], tablePKs)...)
+	}
+
+	// change tables
+	for _, td := range diff.ChangedTables {
+		s.alterTable(td)
+	}
+
+	// foreign keys and indexes of changed tables
+	for _, td := range diff.ChangedTables {
+		deferred = append(deferred, td.addedForeignKeys...)
+	}
+	for _, fk := range deferred {
+		s.addForeignKey(fk)
+	}
+	for _, td := range diff.ChangedTables {
+		for _, c := range td.AddedIndexes {
+			s.createIndex(td.To.Name, c.Name)
+		}
+	}
+
+	// associative entity
+	for _, td := range diff.ChangedTables {
+		for _, c := range td.AddedAssociations {
+			s.createAssociativeTable(td.To, c)
+		}
+	}
+	for _, t := range diff.AddedTables {
+		for _, c := range t.Columns {
+			if c.LinkTable != "" && c.AssociativeEntity {
+				s.createAssociativeTable(t, c)
+			}
+		}
+	}
+	return nil
+}
+
+// alterTable writes ALTER TABLE statements of the columns and the primary key.
+func (s *sqlWriter) alterTable(td *TableDiff) {
+	o, md, d := s.o, s.d, s.d.Dialect
+	caps := md.Capabilities()
+	name := td.To.Name
+	sqlType := s.sqlType(td.To)
+
+	fromPKs := primaryKeys([]*Table{td.From})[name]
+	toPKs := primaryKeys([]*Table{td.To})[name]
+	if td.PrimaryKeyChanged && len(fromPKs) > 0 {
+		pkName := o.naming.primaryKey(d, name, fromPKs)
+		if pkName == "" {
+			pkName = d.ConstraintNaming().primaryKey(d, name, fromPKs)
+		}
+		clause := md.DropPrimaryKey("")
+		if pkName != "" {
+			clause = md.DropPrimaryKey(s.q(pkName))
+		}
+		if caps.NoAlterPrimaryKey {
+			s.comment("ALTER TABLE %s DROP PRIMARY KEY (%s can't change primary keys of existing tables)", s.table(name), d)
+		} else if clause == "" {
+			s.comment("ALTER TABLE %s DROP PRIMARY KEY (the name of the primary key is unknown)", s.table(name))
+		} else {
+			s.statement("ALTER TABLE %s %s", s.table(name), clause)
+		}
+	}
+
+	for _, c := range td.AddedColumns {
+		var definition string
+		if c.PrimaryKey {
+			if c.AutoIncrement {
+				s.sequence(name, c.Name)
+				definition = md.keyType(c) + s.sequenceDefault(name, c.Name)
+			} else {
+				definition = sqlType(md.keyType(c)) + " NOT NULL"
+			}
+		} else {
+			definition = s.columnDefinition(c, sqlType)
+		}
+		clause := md.AddColumn(s.q(c.Name) + " " + definition)
+		if caps.NoAddRequiredColumn && (c.PrimaryKey || !c.Nullable) {
+			s.comment("ALTER TABLE %s %s (%s can't add NOT NULL columns without default values)", s.table(name), clause, d)
+		} else {
+			s.statement("ALTER TABLE %s %s", s.table(name), clause)
+		}
+		if c.PrimaryKey && c.AutoIncrement {
+			s.trigger(name, c.Name)
+		}
+	}
+
+	for _, cd := range td.ChangedColumns {
+		fromType := s.alterType(cd.From, sqlType)
+		toType := s.alterType(cd.To, sqlType)
+		change := ColumnChange{
+			Type:            toType,
+			Nullable:        cd.To.Nullable && !cd.To.PrimaryKey,
+			TypeChanged:     fromType != toType,
+			NullableChanged: cd.NullableChanged() && !cd.To.PrimaryKey,
+		}
+		if change.Nullable {
+			change.Type = md.NullableType(toType)
+		}
+		if !change.TypeChanged && !change.NullableChanged {
+			continue
+		}
+		if caps.NoAlterColumn {
+			s.comment("ALTER TABLE %s ALTER COLUMN %s %s (%s can't change columns of existing tables)", s.table(name), s.q(cd.To.Name), change.Definition(), d)
+			continue
+		}
+		for _, clause := range md.AlterColumn(s.q(cd.To.Name), change) {
+			s.statement("ALTER TABLE %s %s", s.table(name), clause)
+		}
+	}
+
+	for _, c := range td.DroppedColumns {
+		if caps.NoAlterForeignKey && c.LinkTable != "" && !caps.NoForeignKey {
+			s.comment("ALTER TABLE %s DROP COLUMN %s (%s can't drop columns of foreign keys)", s.table(name), s.q(c.Name), d)
+			continue
+		}
+		s.statement("ALTER TABLE %s DROP COLUMN %s", s.table(name), s.q(c.Name))
+		if c.PrimaryKey && c.AutoIncrement {
+			if caps.SequenceTrigger {
+				s.statement("DROP TRIGGER %s", s.q(triggerName(d, name, c.Name)))
+			}
+			s.dropSequence(name, c.Name)
+		}
+	}
+
+	if td.PrimaryKeyChanged && len(toPKs) > 0 {
+		if caps.NoAlterPrimaryKey {
+			s.comment("ALTER TABLE %s ADD PRIMARY KEY(%s) (%s can't change primary keys of existing tables)", s.table(name), s.ql(toPKs), d)
+		} else {
+			s.statement("ALTER TABLE %s ADD %s", s.table(name), s.primaryKey(o.naming.primaryKey(d, name, toPKs), toPKs))
+		}
+	}
+}
+
+// alterType returns the SQL type of the column without auto-increment modifiers to change the column.
+func (s *sqlWriter) alterType(c *Column, sqlType func(string) string) string {
+	return sqlType(s.d.columnType(c))
+}
+
+// foreignKeyName returns the name of the foreign key to drop it.
+// The name that the database gives is used if the naming convention doesn't name it.
+func (s *sqlWriter) foreignKeyName(fk *foreignKey) string {
+	d := s.d.Dialect
+	if name := s.o.naming.foreignKey(d, fk); name != "" {
+		return name
+	}
+	return d.ConstraintNaming().foreignKey(d, fk)
+}
+
+// dropForeignKey writes ALTER TABLE that drops the foreign key.
+func (s *sqlWriter) dropForeignKey(fk *foreignKey) {
+	caps := s.d.Capabilities()
+	if caps.NoForeignKey {
+		return
+	}
+	name := s.foreignKeyName(fk)
+	if caps.NoAlterForeignKey {
+		s.comment("ALTER TABLE %s DROP %s (%s can't drop foreign keys of existing tables)", s.table(fk.Table), s.foreignKey(fk, name), s.d.Dialect)
+	} else if name == "" {
+		s.comment("ALTER TABLE %s DROP %s (the name of the foreign key is unknown)", s.table(fk.Table), s.foreignKey(fk, name))
+	} else {
+		s.statement("ALTER TABLE %s %s", s.table(fk.Table), s.d.DropForeignKey(s.q(name)))
+	}
+}
+
+// dropIndex writes the statement that drops the unique index of the column.
+func (s *sqlWriter) dropIndex(table, column string) {
+	if s.d.Capabilities().NoUniqueIndex {
+		return
+	}
+	name := s.o.naming.unique(s.d.Dialect, table, []string{column})
+	if name == "" {
+		s.comment("DROP INDEX ON %s(%s) (the name of the index is unknown)", s.table(table), s.q(column))
+		return
+	}
+	// the clause is put before the name because the dialect formats the statement
+	s.statement("%s", s.d.DropIndex(s.ifExists()+s.q(name), s.table(table)))
+}
+
+// dropTable writes DROP TABLE and drops the sequences of the auto-increment keys.
+func (s *sqlWriter) dropTable(t *Table) {
+	s.statement("DROP TABLE %s%s%s", s.ifExists(), s.table(t.Name), s.cascade())
+	for _, c := range t.Columns {
+		if c.PrimaryKey && c.AutoIncrement {
+			s.dropSequence(t.Name, c.Name)
+		}
+	}
+}
+
+// dropAssociativeTable writes DROP TABLE of the associative entity column c of the table t.
+func (s *sqlWriter) dropAssociativeTable(t *Table, c *Column) {
+	name := t.Name + "_" + c.Name
+	s.statement("DROP TABLE %s%s%s", s.ifExists(), s.table(name), s.cascade())
+	s.dropSequence(name, "id")
+}
+
+// dropSequence drops the sequence of the auto-increment key if the dialect implements it by a sequence.
+func (s *sqlWriter) dropSequence(table, column string) {
+	if s.d.Capabilities().SequenceTrigger || s.d.Capabilities().SequenceDefault {
+		s.statement("DROP SEQUENCE %s%s", s.ifExists(), s.q(sequenceName(s.d.Dialect, table, column)))
+	}
+}
+
+// cascade returns the CASCADE clause of DROP TABLE for WithDropTables.
+func (s *sqlWriter) cascade() string {
+	if !s.teardown {
+		return ""
+	}
+	return s.d.DropTableCascade()
+}
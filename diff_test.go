@@ -0,0 +1,399 @@
+package md2sql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseForTest(t *testing.T, src string) []*Table {
+	t.Helper()
+	tables, err := Parse(strings.NewReader(src))
+	assert.NoError(t, err)
+	return tables
+}
+
+func TestDiff(t *testing.T) {
+	from := parseForTest(t, TrimIndent(t, `
+	* table: User
+	  * @id
+	  * name: string
+	  * $email: string
+	  * age: integer
+	  * job: *Job.id?
+	* table: Job
+	  * @id
+	* table: Tag
+	  * @id
+	`))
+	to := parseForTest(t, TrimIndent(t, `
+	* table: User
+	  * @id
+	  * name: varchar(100)
+	  * email: string
+	  * age: int32?
+	  * tags: *Tag.id[]
+	* table: Tag
+	  * @id
+	* table: Team
+	  * @id
+	`))
+	diff, err := Diff(from, to)
+	assert.NoError(t, err)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, "Team", diff.AddedTables[0].Name)
+	assert.Equal(t, "Job", diff.DroppedTables[0].Name)
+	assert.Len(t, diff.ChangedTables, 1)
+
+	td := diff.ChangedTables[0]
+	assert.Equal(t, "User", td.To.Name)
+	assert.Empty(t, td.AddedColumns)
+	assert.Equal(t, "job", td.DroppedColumns[0].Name)
+	assert.Len(t, td.ChangedColumns, 2)
+	assert.Equal(t, "name", td.ChangedColumns[0].To.Name)
+	assert.True(t, td.ChangedColumns[0].TypeChanged())
+	assert.False(t, td.ChangedColumns[0].NullableChanged())
+	assert.Equal(t, "age", td.ChangedColumns[1].To.Name)
+	assert.False(t, td.ChangedColumns[1].TypeChanged()) // int32 is an alias of integer
+	assert.True(t, td.ChangedColumns[1].NullableChanged())
+	assert.False(t, td.PrimaryKeyChanged)
+	assert.Empty(t, td.AddedIndexes)
+	assert.Equal(t, "email", td.DroppedIndexes[0].Name)
+	assert.Equal(t, "tags", td.AddedAssociations[0].Name)
+	assert.Empty(t, td.DroppedAssociations)
+}
+
+func TestDiff_NoDifference(t *testing.T) {
+	src := TrimIndent(t, `
+	* table: User
+	  * @id
+	  * name: string
+	  * job: *Job.id
+	* table: Job
+	  * @id
+	`)
+	diff, err := Diff(parseForTest(t, src), parseForTest(t, src))
+	assert.NoError(t, err)
+	assert.True(t, diff.IsEmpty())
+}
+
+func TestMigration(t *testing.T) {
+	type args struct {
+		from    string
+		to      string
+		dialect Dialect
+		opts    []Option
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "add table and column",
+			args: args{
+				from: TrimIndent(t, `
+				* table: User
+				  * @id
+				`),
+				to: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * $name: string
+				  * job: *Job.id?
+				* table: Job
+				  * @id
+				`),
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE Job(
+				id SERIAL,
+				PRIMARY KEY(id)
+			);
+
+			ALTER TABLE User ADD COLUMN name TEXT NOT NULL;
+
+			ALTER TABLE User ADD COLUMN job INTEGER;
+
+			ALTER TABLE User ADD FOREIGN KEY(job) REFERENCES Job(id);
+
+			CREATE UNIQUE INDEX INDEX_User_name ON User(name);
+			`),
+		},
+		{
+			name: "drop table and associative entity",
+			args: args{
+				from: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * tags: *Tag.id[]
+				  * job: *Job.id
+				* table: Job
+				  * @id
+				* table: Tag
+				  * @id
+				`),
+				to: TrimIndent(t, `
+				* table: User
+				  * @id
+				* table: Tag
+				  * @id
+				`),
+			},
+			want: TrimIndent(t, `
+			ALTER TABLE User DROP CONSTRAINT User_job_fkey;
+
+			DROP TABLE User_tags;
+
+			DROP TABLE Job;
+
+			ALTER TABLE User DROP COLUMN job;
+			`),
+		},
+		{
+			name: "change columns on PostgreSQL",
+			args: args{
+				from: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * name: string
+				  * age: integer
+				`),
+				to: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * name: varchar(100)?
+				  * age: int32
+				`),
+			},
+			want: TrimIndent(t, `
+			ALTER TABLE User ALTER COLUMN name TYPE VARCHAR(100);
+
+			ALTER TABLE User ALTER COLUMN name DROP NOT NULL;
+			`),
+		},
+		{
+			name: "change columns on MySQL",
+			args: args{
+				from: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * $name: string
+				  * age: integer?
+				`),
+				to: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * name: varchar(100)
+				  * age: bigint
+				`),
+				dialect: MySQL,
+			},
+			want: TrimIndent(t, `
+			DROP INDEX INDEX_User_name ON User;
+
+			ALTER TABLE User MODIFY COLUMN name VARCHAR(100) NOT NULL;
+
+			ALTER TABLE User MODIFY COLUMN age BIGINT NOT NULL;
+			`),
+		},
+		{
+			name: "change columns on Oracle",
+			args: args{
+				from: TrimIndent(t, `
+				* table: Users
+				  * @id
+				  * name: string
+				  * age: integer?
+				`),
+				to: TrimIndent(t, `
+				* table: Users
+				  * @id
+				  * name: varchar(100)
+				  * age: bigint
+				`),
+				dialect: Oracle,
+			},
+			want: TrimIndent(t, `
+			ALTER TABLE USERS MODIFY NAME VARCHAR2(100);
+
+			ALTER TABLE USERS MODIFY AGE NUMBER(19);
+
+			ALTER TABLE USERS MODIFY AGE NOT NULL;
+			`),
+		},
+		{
+			name: "SQLite can't change columns",
+			args: args{
+				from: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * name: string
+				`),
+				to: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * name: string?
+				  * age: integer
+				  * memo: text?
+				`),
+				dialect: SQLite,
+			},
+			want: TrimIndent(t, `
+			-- ALTER TABLE User ADD COLUMN age INTEGER NOT NULL (SQLite can't add NOT NULL columns without default values);
+
+			ALTER TABLE User ADD COLUMN memo TEXT;
+
+			-- ALTER TABLE User ALTER COLUMN name TEXT (SQLite can't change columns of existing tables);
+			`),
+		},
+		{
+			name: "change primary key",
+			args: args{
+				from: TrimIndent(t, `
+				* table: Tag
+				  * @id
+				  * code: varchar(10)
+				* table: Post
+				  * @id
+				  * tag: *Tag.id
+				`),
+				to: TrimIndent(t, `
+				* table: Tag
+				  * @code: varchar(10)
+				* table: Post
+				  * @id
+				  * tag: *Tag.code
+				`),
+			},
+			want: TrimIndent(t, `
+			ALTER TABLE Post DROP CONSTRAINT Post_tag_fkey;
+
+			ALTER TABLE Tag DROP CONSTRAINT Tag_pkey;
+
+			ALTER TABLE Tag DROP COLUMN id;
+
+			ALTER TABLE Tag ADD PRIMARY KEY(code);
+
+			ALTER TABLE Post ALTER COLUMN tag TYPE VARCHAR(10);
+
+			ALTER TABLE Post ADD FOREIGN KEY(tag) REFERENCES Tag(code);
+			`),
+		},
+		{
+			name: "unknown constraint names",
+			args: args{
+				from: TrimIndent(t, `
+				* table: Tag
+				  * @id
+				* table: Post
+				  * @id
+				  * tag: *Tag.id
+				`),
+				to: TrimIndent(t, `
+				* table: Tag
+				  * @code: varchar(10)
+				* table: Post
+				  * @id
+				`),
+				dialect: SQLServer,
+			},
+			want: TrimIndent(t, `
+			-- ALTER TABLE [Post] DROP FOREIGN KEY([tag]) REFERENCES [Tag]([id]) (the name of the foreign key is unknown);
+
+			-- ALTER TABLE [Tag] DROP PRIMARY KEY (the name of the primary key is unknown);
+
+			ALTER TABLE [Tag] ADD [code] NVARCHAR(10) NOT NULL;
+			GO
+
+			ALTER TABLE [Tag] DROP COLUMN [id];
+			GO
+
+			ALTER TABLE [Tag] ADD PRIMARY KEY([code]);
+			GO
+
+			ALTER TABLE [Post] DROP COLUMN [tag];
+			GO
+			`),
+		},
+		{
+			name: "standard naming",
+			args: args{
+				from: TrimIndent(t, `
+				* table: Post
+				  * @id
+				  * $slug: string
+				  * author: *User.id
+				* table: User
+				  * @id
+				`),
+				to: TrimIndent(t, `
+				* table: Post
+				  * @id
+				  * slug: string
+				  * editor: *User.id
+				* table: User
+				  * @id
+				`),
+				dialect: MySQL,
+				opts:    []Option{WithNamingConvention(StandardNamingConvention)},
+			},
+			want: TrimIndent(t, `
+			ALTER TABLE Post DROP FOREIGN KEY fk_Post_author_User;
+
+			DROP INDEX uq_Post_slug ON Post;
+
+			ALTER TABLE Post ADD COLUMN editor BIGINT UNSIGNED NOT NULL;
+
+			ALTER TABLE Post DROP COLUMN author;
+
+			ALTER TABLE Post ADD CONSTRAINT fk_Post_editor_User FOREIGN KEY(editor) REFERENCES User(id);
+			`),
+		},
+		{
+			name: "sequences of Oracle 11g",
+			args: args{
+				from: TrimIndent(t, `
+				* table: Job
+				  * @id
+				`),
+				to: TrimIndent(t, `
+				* table: Task
+				  * @id
+				`),
+				dialect: Oracle11g,
+			},
+			want: TrimIndent(t, `
+			DROP TABLE JOB;
+
+			DROP SEQUENCE JOB_ID_SEQ;
+
+			CREATE SEQUENCE TASK_ID_SEQ;
+
+			CREATE TABLE TASK(
+				ID NUMBER(19),
+				PRIMARY KEY(ID)
+			);
+
+			CREATE OR REPLACE TRIGGER TASK_ID_TRG
+			BEFORE INSERT ON TASK
+			FOR EACH ROW
+			WHEN (new.ID IS NULL)
+			BEGIN
+				:new.ID := TASK_ID_SEQ.NEXTVAL;
+			END;
+			/
+			`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := DumpMigration(w, parseForTest(t, tt.args.from), parseForTest(t, tt.args.to), tt.args.dialect, tt.args.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, w.String())
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package md2sql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Drift compares the tables of the database with the tables of the design, and returns the difference
+// that migrates the database to the design. Names are matched case-insensitively and the types of both sides
+// are compared after the conversion of the dialect with the type mapping, so types that the dialect doesn't distinguish are the same.
+// The difference has the types of the design as written.
+func Drift(db *sql.DB, tables []*Table, d Dialect, opts ...Option) (*SchemaDiff, error) {
+	md := newOption(opts).dialect(d)
+	actual, err := Introspect(db, md)
+	if err != nil {
+		return nil, err
+	}
+	design, err := copyTables(tables)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fixRelations(design); err != nil {
+		return nil, err
+	}
+	matchNames(actual, design)
+	p := &ddlParser{d: md}
+	written := make(map[*Column]string)
+	for _, t := range design {
+		for _, c := range t.Columns {
+			if c.Type != "" && c.LinkTable == "" {
+				written[c] = c.Type
+				nullable := c.Nullable
+				c.Type = p.columnPortableType(md.columnType(c), c.PrimaryKey || c.Index, &nullable)
+			}
+		}
+	}
+	diff, err := Diff(actual, design)
+	for c, t := range written {
+		c.Type = t
+	}
+	return diff, err
+}
+
+// copyTables returns the deep copy of the tables so as not to modify the parsed tables.
+func copyTables(tables []*Table) ([]*Table, error) {
+	src, err := json.Marshal(tables)
+	if err != nil {
+		return nil, err
+	}
+	var result []*Table
+	err = json.Unmarshal(src, &result)
+	return result, err
+}
+
+// matchNames renames the tables and the columns of the database to the names of the design
+// if they differ only in case like "user" of PostgreSQL.
+func matchNames(actual, design []*Table) {
+	tableNames := make(map[string]string)
+	columnNames := make(map[string]map[string]string)
+	for _, t := range design {
+		tableNames[strings.ToLower(t.Name)] = t.Name
+		columns := make(map[string]string)
+		for _, c := range t.Columns {
+			columns[strings.ToLower(c.Name)] = c.Name
+		}
+		columnNames[strings.ToLower(t.Name)] = columns
+	}
+	rename := func(table, column string) (string, string) {
+		if name, ok := columnNames[strings.ToLower(table)][strings.ToLower(column)]; ok {
+			column = name
+		}
+		if name, ok := tableNames[strings.ToLower(table)]; ok {
+			table = name
+		}
+		return table, column
+	}
+	for _, t := range actual {
+		for _, c := range t.Columns {
+			if c.LinkTable != "" {
+				c.LinkTable, c.LinkColumn = rename(c.LinkTable, c.LinkColumn)
+			}
+			_, c.Name = rename(t.Name, c.Name)
+		}
+		t.Name, _ = rename(t.Name, "")
+	}
+}
+
+// DumpDrift writes the report of the difference returned by Drift. The database is compared with the design,
+// so "missing" means that the design has it but the database doesn't, and "extra" is the opposite.
+func DumpDrift(w io.Writer, diff *SchemaDiff) error {
+	if diff.IsEmpty() {
+		io.WriteString(w, "No drift\n")
+		return nil
+	}
+	for _, t := range diff.AddedTables {
+		fmt.Fprintf(w, "missing table: %s\n", t.Name)
+	}
+	for _, t := range diff.DroppedTables {
+		fmt.Fprintf(w, "extra table: %s\n", t.Name)
+	}
+	nullability := func(c *Column) string {
+		if c.Nullable {
+			return "NULL"
+		}
+		return "NOT NULL"
+	}
+	for _, td := range diff.ChangedTables {
+		fmt.Fprintf(w, "table %s:\n", td.To.Name)
+		for _, c := range td.AddedColumns {
+			fmt.Fprintf(w, "  missing column: %s\n", c.Name)
+		}
+		for _, c := range td.DroppedColumns {
+			fmt.Fprintf(w, "  extra column: %s\n", c.Name)
+		}
+		for _, cd := range td.ChangedColumns {
+			if cd.TypeChanged() {
+				fmt.Fprintf(w, "  type mismatch: %s (database: %s, design: %s)\n", cd.To.Name, driftType(cd.From), driftType(cd.To))
+			}
+			if cd.NullableChanged() {
+				fmt.Fprintf(w, "  nullability mismatch: %s (database: %s, design: %s)\n", cd.To.Name, nullability(cd.From), nullability(cd.To))
+			}
+		}
+		if td.PrimaryKeyChanged {
+			fmt.Fprintf(w, "  primary key mismatch (database: %s, design: %s)\n",
+				strings.Join(primaryKeys([]*Table{td.From})[td.From.Name], ", "),
+				strings.Join(primaryKeys([]*Table{td.To})[td.To.Name], ", "))
+		}
+		for _, c := range td.AddedIndexes {
+			fmt.Fprintf(w, "  missing unique index: %s\n", c.Name)
+		}
+		for _, c := range td.DroppedIndexes {
+			fmt.Fprintf(w, "  extra unique index: %s\n", c.Name)
+		}
+		for _, fk := range td.addedForeignKeys {
+			fmt.Fprintf(w, "  missing foreign key: %s\n", driftForeignKey(fk))
+		}
+		for _, fk := range td.droppedForeignKeys {
+			fmt.Fprintf(w, "  extra foreign key: %s\n", driftForeignKey(fk))
+		}
+		for _, c := range td.AddedAssociations {
+			fmt.Fprintf(w, "  missing associative entity: %s\n", c.Name)
+		}
+		for _, c := range td.DroppedAssociations {
+			fmt.Fprintf(w, "  extra associative entity: %s\n", c.Name)
+		}
+	}
+	return nil
+}
+
+// driftType returns the type of the column for the report like "auto-increment" of "@id" and "*Job.id" of foreign keys.
+func driftType(c *Column) string {
+	if c.LinkTable != "" {
+		return "*" + c.LinkTable + "." + c.LinkColumn
+	}
+	if c.AutoIncrement {
+		return "auto-increment"
+	}
+	return c.Type
+}
+
+func driftForeignKey(fk *foreignKey) string {
+	return fmt.Sprintf("(%s) -> %s(%s)", strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "))
+}
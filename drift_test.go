@@ -0,0 +1,135 @@
+package md2sql
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrift(t *testing.T) {
+	deployed := TrimIndent(t, `
+	* table: User
+	  * @id
+	  * name: string
+	  * $email: string
+	  * age: integer?
+	  * job: *Job.id?
+	  * tags: *Tag.id[]
+	* table: Job
+	  * @id
+	  * name: text
+	* table: Tag
+	  * @id
+	* table: Legacy
+	  * @id
+	`)
+	design := TrimIndent(t, `
+	* table: User
+	  * @id
+	  * name: string
+	  * email: string
+	  * $nickname: string?
+	  * age: double
+	  * job: *Job.id?
+	  * team: *Team.id?
+	* table: Job
+	  * @id
+	  * name: string
+	* table: Team
+	  * @id
+	  * name: string
+	* table: Tag
+	  * @id
+	`)
+	db := openSQLite(t)
+	var ddl bytes.Buffer
+	assert.NoError(t, DumpSQL(&ddl, parseForTest(t, deployed), SQLite))
+	execSQLite(t, db, ddl.String())
+
+	t.Run("same design", func(t *testing.T) {
+		diff, err := Drift(db, parseForTest(t, deployed), SQLite)
+		assert.NoError(t, err)
+		var out bytes.Buffer
+		assert.NoError(t, DumpDrift(&out, diff))
+		assert.Equal(t, "No drift\n", out.String())
+	})
+	t.Run("changed design", func(t *testing.T) {
+		tables := parseForTest(t, design)
+		diff, err := Drift(db, tables, SQLite)
+		assert.NoError(t, err)
+		var out bytes.Buffer
+		assert.NoError(t, DumpDrift(&out, diff))
+		assert.Equal(t, TrimIndent(t, `
+		missing table: Team
+		extra table: Legacy
+		table User:
+		  missing column: nickname
+		  missing column: team
+		  type mismatch: age (database: integer, design: double)
+		  nullability mismatch: age (database: NULL, design: NOT NULL)
+		  missing unique index: nickname
+		  extra unique index: email
+		  missing foreign key: (team) -> Team(id)
+		  extra associative entity: tags
+		`)+"\n", out.String())
+		// the design isn't modified
+		assert.Equal(t, "", tables[0].Columns[0].Type)
+
+	})
+	t.Run("corrective migration", func(t *testing.T) {
+		// SQLite can add tables, columns and indexes
+		tables := parseForTest(t, TrimIndent(t, `
+		* table: User
+		  * @id
+		  * name: string
+		  * $email: string
+		  * $nickname: string?
+		  * age: integer?
+		  * job: *Job.id?
+		  * tags: *Tag.id[]
+		* table: Job
+		  * @id
+		  * name: text
+		* table: Tag
+		  * @id
+		* table: Team
+		  * @id
+		  * name: string
+		`))
+		diff, err := Drift(db, tables, SQLite)
+		assert.NoError(t, err)
+		assert.False(t, diff.IsEmpty())
+		var migration bytes.Buffer
+		assert.NoError(t, diff.DumpSQL(&migration, SQLite))
+		execSQLite(t, db, migration.String())
+
+		diff, err = Drift(db, tables, SQLite)
+		assert.NoError(t, err)
+		var out bytes.Buffer
+		assert.NoError(t, DumpDrift(&out, diff))
+		assert.Equal(t, "No drift\n", out.String())
+	})
+}
+
+func TestDrift_TypeMapping(t *testing.T) {
+	src := TrimIndent(t, `
+	* table: User
+	  * @id
+	  * name: string
+	  * note: varchar(100)?
+	  * bio: text?
+	`)
+	mapping := WithTypeMapping(TypeMapping{SQLite: {"string": "VARCHAR(255)"}})
+	db := openSQLite(t)
+	var ddl bytes.Buffer
+	assert.NoError(t, DumpSQL(&ddl, parseForTest(t, src), SQLite, mapping))
+	execSQLite(t, db, ddl.String())
+
+	// the database deployed with the type mapping doesn't drift
+	diff, err := Drift(db, parseForTest(t, src), SQLite, mapping)
+	assert.NoError(t, err)
+	var out bytes.Buffer
+	assert.NoError(t, DumpDrift(&out, diff))
+	assert.Equal(t, "No drift\n", out.String())
+}
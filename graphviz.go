@@ -19,8 +19,9 @@ var graphvizCN = map[Cardinality]string{
 	ZeroOrMore: "crow",
 }
 
-func DumpGraphviz(w io.Writer, tables []*Table, m ModelType, d Dialect) error {
-	relations, err := fixRelations(tables, d)
+func DumpGraphviz(w io.Writer, tables []*Table, m ModelType, d Dialect, opts ...Option) error {
+	md := newOption(opts).dialect(d)
+	relations, err := fixRelations(tables)
 	if err != nil {
 		return err
 	}
@@ -46,7 +47,7 @@ func DumpGraphviz(w io.Writer, tables []*Table, m ModelType, d Dialect) error {
 			if !c.PrimaryKey {
 				continue
 			}
-			fmt.Fprintf(w, "\t\t\t<tr><td align=\"left\">PK&nbsp;<b>%s</b>&nbsp;<i><font color=\"lightgray\">%s</font></i></td></tr>\n", c.Name, d.PrimaryKeyBaseType(c.Type))
+			fmt.Fprintf(w, "\t\t\t<tr><td align=\"left\">PK&nbsp;<b>%s</b>&nbsp;<i><font color=\"lightgray\">%s</font></i></td></tr>\n", c.Name, md.baseType(c))
 		}
 		fmt.Fprintf(w, "\t\t</table>\n")
 
@@ -60,7 +61,7 @@ func DumpGraphviz(w io.Writer, tables []*Table, m ModelType, d Dialect) error {
 			cst := ""
 			if c.LinkTable != "" {
 				if !c.AssociativeEntity {
-					tn = d.PrimaryKeyBaseType(c.Type)
+					tn = md.baseType(c)
 					if c.Nullable {
 						cst = "FK&nbsp;"
 					} else {
@@ -68,10 +69,10 @@ func DumpGraphviz(w io.Writer, tables []*Table, m ModelType, d Dialect) error {
 					}
 				}
 			} else if c.Nullable {
-				tn = d.TypeConversion(c.Type)
+				tn = md.columnType(c)
 			} else {
 				cst = "*"
-				tn = d.TypeConversion(c.Type)
+				tn = md.columnType(c)
 			}
 			fmt.Fprintf(w, "\t\t\t<tr><td align=\"left\">%s<b>%s</b>&nbsp;<i><font color=\"lightgray\">%s</font></i></td></tr>\n", cst, c.Name, tn)
 		}
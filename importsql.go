@@ -0,0 +1,725 @@
+package md2sql
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type sqlTokenKind int
+
+const (
+	wordToken sqlTokenKind = iota
+	quotedToken
+	stringToken
+	symbolToken
+)
+
+// sqlToken is a token of SQL. Quoted identifiers are unquoted.
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+// is returns true if the token is the keyword.
+func (t sqlToken) is(keyword string) bool {
+	return t.kind == wordToken && strings.EqualFold(t.text, keyword)
+}
+
+func (t sqlToken) isSymbol(symbol string) bool {
+	return t.kind == symbolToken && t.text == symbol
+}
+
+func (t sqlToken) isName() bool {
+	return t.kind == wordToken || t.kind == quotedToken
+}
+
+// tokenizeSQL splits SQL into tokens without comments.
+func tokenizeSQL(src string) ([]sqlToken, error) {
+	var result []sqlToken
+	quoted := func(i int, open, close byte, kind sqlTokenKind) (int, error) {
+		var b strings.Builder
+		for j := i + 1; j < len(src); j++ {
+			if src[j] != close {
+				b.WriteByte(src[j])
+			} else if j+1 < len(src) && src[j+1] == close {
+				b.WriteByte(close)
+				j++
+			} else {
+				result = append(result, sqlToken{kind: kind, text: b.String()})
+				return j + 1, nil
+			}
+		}
+		return 0, fmt.Errorf("unterminated %c%c: %s", open, close, firstLine(src[i:]))
+	}
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		var err error
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case strings.HasPrefix(src[i:], "--"):
+			if end := strings.IndexByte(src[i:], '\n'); end != -1 {
+				i += end + 1
+			} else {
+				i = len(src)
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated comment: %s", firstLine(src[i:]))
+			}
+			i += end + 4
+		case r == '\'':
+			i, err = quoted(i, '\'', '\'', stringToken)
+		case r == '"':
+			i, err = quoted(i, '"', '"', quotedToken)
+		case r == '`':
+			i, err = quoted(i, '`', '`', quotedToken)
+		case r == '[' && !strings.HasPrefix(src[i:], "[]"):
+			i, err = quoted(i, '[', ']', quotedToken)
+		case r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			start := i
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if r != '_' && r != '$' && r != '#' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+			result = append(result, sqlToken{kind: wordToken, text: src[start:i]})
+		default:
+			result = append(result, sqlToken{kind: symbolToken, text: src[i : i+size]})
+			i += size
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func firstLine(src string) string {
+	line, _, _ := strings.Cut(src, "\n")
+	return line
+}
+
+// tokenReader reads tokens of a statement.
+type tokenReader struct {
+	tokens []sqlToken
+	pos    int
+}
+
+func (r *tokenReader) eof() bool {
+	return r.pos >= len(r.tokens)
+}
+
+func (r *tokenReader) peek() sqlToken {
+	if r.eof() {
+		return sqlToken{kind: symbolToken}
+	}
+	return r.tokens[r.pos]
+}
+
+func (r *tokenReader) next() sqlToken {
+	t := r.peek()
+	r.pos++
+	return t
+}
+
+// accept consumes the keywords if the following tokens are them.
+func (r *tokenReader) accept(keywords ...string) bool {
+	if r.pos+len(keywords) > len(r.tokens) {
+		return false
+	}
+	for i, keyword := range keywords {
+		if !r.tokens[r.pos+i].is(keyword) {
+			return false
+		}
+	}
+	r.pos += len(keywords)
+	return true
+}
+
+// name reads the name qualified by schemas like "public.users" and returns the last part.
+func (r *tokenReader) name() (string, bool) {
+	if !r.peek().isName() {
+		return "", false
+	}
+	name := r.next().text
+	for r.peek().isSymbol(".") && r.pos+1 < len(r.tokens) && r.tokens[r.pos+1].isName() {
+		r.pos++
+		name = r.next().text
+	}
+	return name, true
+}
+
+// group reads the tokens in the parentheses.
+func (r *tokenReader) group() ([]sqlToken, bool) {
+	if !r.peek().isSymbol("(") {
+		return nil, false
+	}
+	start := r.pos + 1
+	depth := 0
+	for !r.eof() {
+		t := r.next()
+		if t.isSymbol("(") {
+			depth++
+		} else if t.isSymbol(")") {
+			depth--
+			if depth == 0 {
+				return r.tokens[start : r.pos-1], true
+			}
+		}
+	}
+	return r.tokens[start:], true
+}
+
+// skipUntilGroup skips the tokens like index names until the parentheses.
+func (r *tokenReader) skipUntilGroup() ([]sqlToken, bool) {
+	for !r.eof() && !r.peek().isSymbol("(") {
+		r.pos++
+	}
+	return r.group()
+}
+
+// splitTokens splits the tokens by the separator outside of parentheses.
+func splitTokens(tokens []sqlToken, separator string) [][]sqlToken {
+	var result [][]sqlToken
+	depth, start := 0, 0
+	for i, t := range tokens {
+		switch {
+		case t.isSymbol("("):
+			depth++
+		case t.isSymbol(")"):
+			depth--
+		case t.isSymbol(separator) && depth == 0:
+			result = append(result, tokens[start:i])
+			start = i + 1
+		}
+	}
+	return append(result, tokens[start:])
+}
+
+// columnNames returns the names of the column list like "(id, name DESC)".
+func columnNames(tokens []sqlToken) []string {
+	var result []string
+	for _, element := range splitTokens(tokens, ",") {
+		if len(element) > 0 && element[0].isName() {
+			result = append(result, element[0].text)
+		}
+	}
+	return result
+}
+
+// renderType joins the tokens of the type like "NUMERIC(10,2)" and "TIMESTAMP(6) WITH TIME ZONE".
+func renderType(tokens []sqlToken) string {
+	var b strings.Builder
+	for i, t := range tokens {
+		if i > 0 && t.isName() && (tokens[i-1].isName() || tokens[i-1].isSymbol(")")) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t.text)
+	}
+	return b.String()
+}
+
+var (
+	// columnConstraints end the type of the column definition.
+	columnConstraints = []string{
+		"NOT", "NULL", "PRIMARY", "UNIQUE", "REFERENCES", "DEFAULT", "CONSTRAINT", "CHECK", "AUTO_INCREMENT",
+		"AUTOINCREMENT", "IDENTITY", "GENERATED", "COLLATE", "COMMENT", "AS", "OPTIONS", "CHARSET", "ON",
+	}
+	// autoIncrementTypes are the pseudo types of auto-increment keys.
+	autoIncrementTypes = []string{"SERIAL", "BIGSERIAL", "SMALLSERIAL", "SERIAL2", "SERIAL4", "SERIAL8"}
+	// autoIncrementMarkers are the keywords and functions that fill keys.
+	autoIncrementMarkers = []string{
+		"AUTO_INCREMENT", "AUTOINCREMENT", "IDENTITY", "NEXTVAL", "GET_NEXT_SEQUENCE_VALUE", "GENERATE_UUID",
+		"GENERATEUUIDV4", "GEN_RANDOM_UUID", "UUID_GENERATE_V4", "UUIDV7", "GEN_ULID",
+	}
+	// tableConstraints start the table constraints in the column list.
+	tableConstraints = []string{"CONSTRAINT", "PRIMARY", "FOREIGN", "UNIQUE", "CHECK", "INDEX", "KEY", "FULLTEXT", "SPATIAL", "EXCLUDE"}
+)
+
+func isKeyword(t sqlToken, keywords []string) bool {
+	for _, keyword := range keywords {
+		if t.is(keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// ddlColumn is a column of CREATE TABLE.
+type ddlColumn struct {
+	name          string
+	sqlType       string
+	notNull       bool
+	autoIncrement bool
+}
+
+// ddlTable is a table of CREATE TABLE and the constraints added to it.
+type ddlTable struct {
+	name        string
+	columns     []*ddlColumn
+	primaryKey  []string
+	uniques     [][]string
+	fks         []*foreignKey
+	annotations map[string]string
+	description string
+}
+
+func (t *ddlTable) column(name string) *ddlColumn {
+	for _, c := range t.columns {
+		if strings.EqualFold(c.name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// ddlParser collects the tables from the statements.
+type ddlParser struct {
+	d      Dialect
+	tables []*ddlTable
+}
+
+func (p *ddlParser) table(name string) *ddlTable {
+	for _, t := range p.tables {
+		if strings.EqualFold(t.name, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// ParseSQL reads CREATE TABLE, CREATE INDEX and ALTER TABLE statements of the dialect and returns the tables.
+// Auto-increment primary keys, single column unique indexes and foreign keys are converted into the Markdown model.
+// Tables that only link two tables are converted into associative entities. Other statements are ignored.
+func ParseSQL(r io.Reader, d Dialect) ([]*Table, error) {
+	if d == nil {
+		d = PostgreSQL
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := tokenizeSQL(string(b))
+	if err != nil {
+		return nil, err
+	}
+	p := &ddlParser{d: d}
+	for _, statement := range splitTokens(tokens, ";") {
+		// batch separators of SQL Server and PL/SQL blocks
+		for len(statement) > 0 && (statement[0].is("GO") || statement[0].isSymbol("/")) {
+			statement = statement[1:]
+		}
+		r := &tokenReader{tokens: statement}
+		if !r.accept("CREATE") {
+			if r.accept("ALTER", "TABLE") {
+				p.alterTable(r)
+			}
+			continue
+		}
+		r.accept("OR", "REPLACE")
+		unique := r.accept("UNIQUE")
+		r.accept("CLUSTERED")
+		r.accept("NONCLUSTERED")
+		if r.accept("INDEX") {
+			p.createIndex(r, unique)
+			continue
+		}
+		for r.accept("GLOBAL") || r.accept("LOCAL") || r.accept("TEMPORARY") || r.accept("TEMP") || r.accept("UNLOGGED") {
+		}
+		if r.accept("TABLE") {
+			p.createTable(r)
+		} else if r.accept("TRIGGER") {
+			p.createTrigger(r)
+		}
+	}
+	return p.result(), nil
+}
+
+func (p *ddlParser) createTable(r *tokenReader) {
+	r.accept("IF", "NOT", "EXISTS")
+	name, ok := r.name()
+	if !ok {
+		return
+	}
+	elements, ok := r.group()
+	if !ok {
+		return
+	}
+	t := &ddlTable{name: name}
+	for _, element := range splitTokens(elements, ",") {
+		if len(element) == 0 {
+			continue
+		}
+		if isKeyword(element[0], tableConstraints) {
+			p.tableConstraint(t, &tokenReader{tokens: element})
+		} else {
+			p.columnDefinition(t, &tokenReader{tokens: element})
+		}
+	}
+	// options after the column list like "PRIMARY KEY(id)" of Spanner and "STRICT" of SQLite
+	for !r.eof() {
+		if r.accept("PRIMARY", "KEY") {
+			if columns, ok := r.group(); ok {
+				t.primaryKey = columnNames(columns)
+			}
+		} else if p.d.Capabilities().OrderByKey && r.accept("ORDER", "BY") {
+			if columns, ok := r.group(); ok {
+				t.primaryKey = columnNames(columns)
+			} else if name, ok := r.name(); ok {
+				t.primaryKey = []string{name}
+			}
+		} else if r.accept("STRICT") {
+			t.annotate("strict")
+		} else if r.accept("WITHOUT", "ROWID") {
+			t.annotate("without_rowid")
+		} else {
+			r.next()
+		}
+	}
+	p.tables = append(p.tables, t)
+}
+
+func (t *ddlTable) annotate(key string) {
+	if t.annotations == nil {
+		t.annotations = make(map[string]string)
+	}
+	t.annotations[key] = ""
+}
+
+func (p *ddlParser) columnDefinition(t *ddlTable, r *tokenReader) {
+	name, ok := r.name()
+	if !ok {
+		return
+	}
+	c := &ddlColumn{name: name}
+	start := r.pos
+	for !r.eof() && !isKeyword(r.peek(), columnConstraints) {
+		if _, ok := r.group(); !ok {
+			r.next()
+		}
+	}
+	typeTokens := r.tokens[start:r.pos]
+	c.sqlType = renderType(typeTokens)
+	for _, t := range typeTokens {
+		if isKeyword(t, autoIncrementTypes) {
+			c.autoIncrement = true
+		}
+	}
+	var primaryKey bool
+	for !r.eof() {
+		switch {
+		case r.accept("NOT", "NULL"):
+			c.notNull = true
+		case r.accept("PRIMARY", "KEY"):
+			primaryKey = true
+		case r.accept("UNIQUE"):
+			t.uniques = append(t.uniques, []string{name})
+		case r.accept("REFERENCES"):
+			p.references(t, r, []string{name})
+		default:
+			if isKeyword(r.next(), autoIncrementMarkers) {
+				c.autoIncrement = true
+			}
+		}
+	}
+	if primaryKey {
+		t.primaryKey = []string{name}
+		// INTEGER PRIMARY KEY of SQLite is the alias of rowid
+		if p.d.Capabilities().InlineAutoIncrementKey && strings.EqualFold(c.sqlType, "INTEGER") {
+			c.autoIncrement = true
+		}
+	}
+	t.columns = append(t.columns, c)
+}
+
+// references reads "REFERENCES table(columns)" of the columns.
+func (p *ddlParser) references(t *ddlTable, r *tokenReader, columns []string) {
+	refTable, ok := r.name()
+	if !ok {
+		return
+	}
+	var refColumns []string
+	if group, ok := r.group(); ok {
+		refColumns = columnNames(group)
+	}
+	t.fks = append(t.fks, &foreignKey{Table: t.name, Columns: columns, RefTable: refTable, RefColumns: refColumns})
+	// skip actions like "ON DELETE CASCADE"
+	for !r.eof() && !isKeyword(r.peek(), []string{"NOT", "NULL", "PRIMARY", "UNIQUE", "REFERENCES"}) {
+		r.next()
+	}
+}
+
+// tableConstraint reads the constraint in the column list or the one added by ALTER TABLE.
+func (p *ddlParser) tableConstraint(t *ddlTable, r *tokenReader) {
+	if r.accept("CONSTRAINT") {
+		r.name()
+	}
+	switch {
+	case r.accept("PRIMARY", "KEY"):
+		if columns, ok := r.skipUntilGroup(); ok {
+			t.primaryKey = columnNames(columns)
+		}
+	case r.accept("UNIQUE"):
+		if columns, ok := r.skipUntilGroup(); ok {
+			t.uniques = append(t.uniques, columnNames(columns))
+		}
+	case r.accept("FOREIGN", "KEY"):
+		if columns, ok := r.skipUntilGroup(); ok && r.accept("REFERENCES") {
+			p.references(t, r, columnNames(columns))
+		}
+	}
+}
+
+// createTrigger reads the trigger that fills the key by the sequence like ":new.ID := USERS_ID_SEQ.NEXTVAL".
+// The statement ends at the first semicolon in the trigger body.
+func (p *ddlParser) createTrigger(r *tokenReader) {
+	var t *ddlTable
+	for !r.eof() && t == nil {
+		if r.accept("ON") {
+			name, _ := r.name()
+			t = p.table(name)
+		} else {
+			r.next()
+		}
+	}
+	for t != nil && !r.eof() {
+		if !r.next().isSymbol(":") || !r.accept("new") || !r.peek().isSymbol(".") {
+			continue
+		}
+		r.next()
+		name, _ := r.name()
+		if c := t.column(name); c != nil && r.next().isSymbol(":") && r.next().isSymbol("=") {
+			for !r.eof() {
+				if r.next().is("NEXTVAL") {
+					c.autoIncrement = true
+				}
+			}
+		}
+	}
+}
+
+func (p *ddlParser) createIndex(r *tokenReader, unique bool) {
+	r.accept("CONCURRENTLY")
+	r.accept("IF", "NOT", "EXISTS")
+	if !r.peek().is("ON") {
+		r.name()
+	}
+	if !r.accept("ON") {
+		return
+	}
+	r.accept("ONLY")
+	name, ok := r.name()
+	t := p.table(name)
+	if !ok || t == nil {
+		return
+	}
+	if columns, ok := r.skipUntilGroup(); ok && unique {
+		t.uniques = append(t.uniques, columnNames(columns))
+	}
+}
+
+func (p *ddlParser) alterTable(r *tokenReader) {
+	r.accept("IF", "EXISTS")
+	r.accept("ONLY")
+	name, ok := r.name()
+	t := p.table(name)
+	if !ok || t == nil {
+		return
+	}
+	for _, clause := range splitTokens(r.tokens[r.pos:], ",") {
+		r := &tokenReader{tokens: clause}
+		if r.accept("ADD") {
+			p.tableConstraint(t, r)
+		} else if r.accept("ALTER") {
+			// "ALTER COLUMN id SET DEFAULT nextval('users_id_seq')" of pg_dump
+			r.accept("COLUMN")
+			name, _ := r.name()
+			if c := t.column(name); c != nil {
+				for !r.eof() {
+					if isKeyword(r.next(), autoIncrementMarkers) {
+						c.autoIncrement = true
+					}
+				}
+			}
+		}
+	}
+}
+
+// result converts the tables into the Markdown model.
+func (p *ddlParser) result() []*Table {
+	var result []*Table
+	// columns are NOT NULL unless their types are wrapped like "Nullable(String)"
+	wrapped := p.d.NullableType("T") != "T"
+	for _, t := range p.tables {
+		table := &Table{Type: EntityTable, Independent: true, Name: t.name, Annotations: t.annotations, Description: t.description}
+		for _, dc := range t.columns {
+			c := &Column{Name: dc.name, Nullable: !dc.notNull && !wrapped}
+			for _, pk := range t.primaryKey {
+				if strings.EqualFold(pk, dc.name) {
+					c.PrimaryKey = true
+					c.Nullable = false
+					c.AutoIncrement = dc.autoIncrement
+				}
+			}
+			for _, unique := range t.uniques {
+				if len(unique) == 1 && strings.EqualFold(unique[0], dc.name) && !c.PrimaryKey {
+					c.Index = true
+				}
+			}
+			for _, fk := range t.fks {
+				for i, column := range fk.Columns {
+					if !strings.EqualFold(column, dc.name) {
+						continue
+					}
+					c.LinkTable = fk.RefTable
+					if i < len(fk.RefColumns) {
+						c.LinkColumn = fk.RefColumns[i]
+					} else if ref := p.table(fk.RefTable); ref != nil && i < len(ref.primaryKey) {
+						c.LinkColumn = ref.primaryKey[i]
+					}
+					c.AutoIncrement = false
+				}
+			}
+			if c.LinkTable == "" && !c.AutoIncrement {
+				c.Type = p.columnPortableType(dc.sqlType, c.PrimaryKey || c.Index, &c.Nullable)
+			}
+			table.Columns = append(table.Columns, c)
+		}
+		result = append(result, table)
+	}
+	return associativeEntities(result)
+}
+
+var (
+	typeSpaces   = regexp.MustCompile(`\s*([(,])\s*|\s+(\))`)
+	typeSynonyms = strings.NewReplacer("CHARACTER VARYING", "VARCHAR", " WITHOUT TIME ZONE", "")
+	// typeNames are the synonyms of the whole type names that the databases report.
+	typeNames = map[string]string{
+		"INT":        "INTEGER",
+		"INT2":       "SMALLINT",
+		"INT4":       "INTEGER",
+		"INT8":       "BIGINT",
+		"BOOL":       "BOOLEAN",
+		"TINYINT(1)": "BOOLEAN",
+	}
+	// portableTypes are the portable type names in the order of preference to convert SQL types.
+	portableTypes = []string{
+		"string", "integer", "bigint", "smallint", "bool", "float", "double", "decimal", "varchar", "char",
+		"date", "timestamp", "timestamptz", "time", "timetz", "text", "uuid", "json", "binary",
+	}
+)
+
+func normalizeSQLType(t string) string {
+	t = typeSynonyms.Replace(typeSpaces.ReplaceAllString(strings.ToUpper(strings.Join(strings.Fields(t), " ")), "$1$2"))
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return t
+}
+
+// portableType converts the SQL type of the dialect into the portable type like "varchar(100)".
+// Types that don't have portable names are lower-cased. Nullable types like "Nullable(String)" are unwrapped.
+func (p *ddlParser) portableType(sqlType string, nullable *bool) string {
+	t := normalizeSQLType(sqlType)
+	if t == "" {
+		// SQLite allows columns without types
+		return "any"
+	}
+	if strings.HasPrefix(t, "NULLABLE(") && strings.HasSuffix(t, ")") {
+		t = strings.TrimSuffix(strings.TrimPrefix(t, "NULLABLE("), ")")
+		*nullable = true
+	}
+	// types without arguments like "NUMBER(10)" of integer are preferred to types with arguments like "NUMBER({args})"
+	for _, name := range portableTypes {
+		if normalizeSQLType(p.d.TypeConversion(name)) == t {
+			return name
+		}
+	}
+	const sentinel = "(12345)"
+	for _, name := range portableTypes {
+		before, after, ok := strings.Cut(normalizeSQLType(p.d.TypeConversion(name+sentinel)), sentinel)
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(t, before+"(") && strings.HasSuffix(t, ")"+after) && len(t) > len(before)+len(after)+2 {
+			return name + "(" + t[len(before)+1:len(t)-len(after)-1] + ")"
+		}
+	}
+	return strings.ToLower(t)
+}
+
+// columnPortableType returns the portable type of the column. Keys and unique columns can have the key types
+// of the dialect like NVARCHAR(450) of SQL Server.
+func (p *ddlParser) columnPortableType(sqlType string, key bool, nullable *bool) string {
+	if key {
+		t := normalizeSQLType(sqlType)
+		for _, name := range portableTypes {
+			if keyType := p.d.PrimaryKeyBaseType(name); keyType != p.d.TypeConversion(name) && normalizeSQLType(keyType) == t {
+				return name
+			}
+		}
+	}
+	return p.portableType(sqlType, nullable)
+}
+
+// associativeEntities converts the tables that only have foreign keys to two tables (and a surrogate key)
+// into the associative entity columns of the first referred table.
+func associativeEntities(tables []*Table) []*Table {
+	referred := make(map[string]bool)
+	byName := make(map[string]*Table)
+	for _, t := range tables {
+		byName[t.Name] = t
+		for _, c := range t.Columns {
+			referred[c.LinkTable] = true
+		}
+	}
+	var result []*Table
+	for _, t := range tables {
+		var links []*Column
+		var others int
+		for _, c := range t.Columns {
+			if c.LinkTable != "" {
+				links = append(links, c)
+			} else if !c.PrimaryKey || !c.AutoIncrement {
+				others++
+			}
+		}
+		if len(links) != 2 || others > 0 || referred[t.Name] || links[0].Index || links[1].Index {
+			result = append(result, t)
+			continue
+		}
+		owner := byName[links[0].LinkTable]
+		name := t.Name
+		if owner != nil && len(t.Name) > len(owner.Name)+1 && strings.EqualFold(t.Name[:len(owner.Name)+1], owner.Name+"_") {
+			name = t.Name[len(owner.Name)+1:]
+		}
+		if owner == nil || !equalStrings(primaryKeys([]*Table{owner})[owner.Name], []string{links[0].LinkColumn}) || hasColumn(owner, name) {
+			result = append(result, t)
+			continue
+		}
+		owner.Columns = append(owner.Columns, &Column{
+			Name:              name,
+			LinkTable:         links[1].LinkTable,
+			LinkColumn:        links[1].LinkColumn,
+			AssociativeEntity: true,
+		})
+	}
+	return result
+}
+
+func hasColumn(t *Table, name string) bool {
+	for _, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return true
+		}
+	}
+	return false
+}
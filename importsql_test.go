@@ -0,0 +1,262 @@
+package md2sql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSQL(t *testing.T) {
+	type args struct {
+		src     string
+		dialect Dialect
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "pg_dump",
+			args: args{
+				src: TrimIndent(t, `
+				-- PostgreSQL database dump
+				SET statement_timeout = 0;
+
+				CREATE TABLE public.users (
+				    id integer NOT NULL,
+				    email character varying(255) NOT NULL,
+				    nickname text,
+				    created_at timestamp(6) without time zone NOT NULL,
+				    job_id integer
+				);
+
+				CREATE SEQUENCE public.users_id_seq AS integer START WITH 1;
+
+				ALTER SEQUENCE public.users_id_seq OWNED BY public.users.id;
+
+				CREATE TABLE public.jobs (
+				    id bigint GENERATED BY DEFAULT AS IDENTITY,
+				    title text NOT NULL,
+				    CONSTRAINT jobs_pkey PRIMARY KEY (id)
+				);
+
+				ALTER TABLE ONLY public.users ALTER COLUMN id SET DEFAULT nextval('public.users_id_seq'::regclass);
+
+				ALTER TABLE ONLY public.users
+				    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+				ALTER TABLE ONLY public.users
+				    ADD CONSTRAINT users_job_id_fkey FOREIGN KEY (job_id) REFERENCES public.jobs(id) ON DELETE SET NULL;
+
+				CREATE UNIQUE INDEX users_email_key ON public.users USING btree (email);
+
+				CREATE INDEX users_created_at_idx ON public.users USING btree (created_at);
+				`),
+			},
+			want: TrimIndent(t, `
+			* table: users
+			  * @id
+			  * $email: varchar(255)
+			  * nickname: string?
+			  * created_at: timestamp(6)
+			  * job_id: *jobs.id?
+
+			* table: jobs
+			  * @id
+			  * title: string
+			`),
+		},
+		{
+			name: "mysqldump",
+			args: args{
+				// backquotes are written as double quotes in the raw string
+				src: strings.ReplaceAll(TrimIndent(t, `
+				/*!40101 SET NAMES utf8mb4 */;
+				DROP TABLE IF EXISTS "tags";
+				CREATE TABLE "tags" (
+				  "id" bigint unsigned NOT NULL AUTO_INCREMENT,
+				  "label" varchar(50) NOT NULL,
+				  PRIMARY KEY ("id"),
+				  UNIQUE KEY "label" ("label")
+				) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+				CREATE TABLE "posts" (
+				  "id" bigint unsigned NOT NULL AUTO_INCREMENT,
+				  "body" text,
+				  "price" decimal(10,2) NOT NULL DEFAULT '0.00' COMMENT 'price; tax included',
+				  PRIMARY KEY ("id")
+				) ENGINE=InnoDB;
+				CREATE TABLE "posts_tags" (
+				  "post_id" bigint unsigned NOT NULL,
+				  "tag_id" bigint unsigned NOT NULL,
+				  PRIMARY KEY ("post_id","tag_id"),
+				  KEY "tag_id" ("tag_id"),
+				  CONSTRAINT "fk_1" FOREIGN KEY ("post_id") REFERENCES "posts" ("id"),
+				  CONSTRAINT "fk_2" FOREIGN KEY ("tag_id") REFERENCES "tags" ("id")
+				);
+				INSERT INTO "tags" VALUES (1,'a;b');
+				`), `"`, "`"),
+				dialect: MySQL,
+			},
+			want: TrimIndent(t, `
+			* table: tags
+			  * @id
+			  * $label: varchar(50)
+
+			* table: posts
+			  * @id
+			  * body: string?
+			  * price: decimal(10,2)
+			  * tags: *tags.id[]
+			`),
+		},
+		{
+			name: "SQL Server",
+			args: args{
+				src: TrimIndent(t, `
+				CREATE TABLE [dbo].[Job](
+					[id] INT IDENTITY(1,1) NOT NULL,
+					[name] NVARCHAR(MAX) NOT NULL,
+					CONSTRAINT [PK_Job] PRIMARY KEY CLUSTERED ([id] ASC)
+				);
+				GO
+
+				CREATE TABLE [dbo].[Employee](
+					[id] INT IDENTITY(1,1) PRIMARY KEY,
+					[code] NCHAR(8) NOT NULL UNIQUE,
+					[job] INT NOT NULL REFERENCES [dbo].[Job]([id]),
+					[boss] INT
+				);
+				GO
+
+				ALTER TABLE [dbo].[Employee] ADD CONSTRAINT [FK_boss] FOREIGN KEY([boss]) REFERENCES [dbo].[Employee]([id]);
+				GO
+				`),
+				dialect: SQLServer,
+			},
+			want: TrimIndent(t, `
+			* table: Job
+			  * @id
+			  * name: string
+
+			* table: Employee
+			  * @id
+			  * $code: char(8)
+			  * job: *Job.id
+			  * boss: *Employee.id?
+			`),
+		},
+		{
+			name: "composite foreign key and link table with columns",
+			args: args{
+				src: TrimIndent(t, `
+				CREATE TABLE Pair(
+					left INTEGER,
+					right INTEGER,
+					PRIMARY KEY(left, right)
+				);
+
+				CREATE TABLE Member(
+					id SERIAL PRIMARY KEY,
+					pair_left INTEGER NOT NULL,
+					pair_right INTEGER NOT NULL,
+					FOREIGN KEY(pair_left, pair_right) REFERENCES Pair(left, right)
+				);
+
+				CREATE TABLE Assignment(
+					member_id INTEGER REFERENCES Member,
+					pair_left INTEGER REFERENCES Pair(left),
+					since DATE NOT NULL
+				);
+				`),
+			},
+			want: TrimIndent(t, `
+			* table: Pair
+			  * @left: integer
+			  * @right: integer
+
+			* table: Member
+			  * @id
+			  * pair_left: *Pair.left
+			  * pair_right: *Pair.right
+
+			* table: Assignment
+			  * member_id: *Member.id?
+			  * pair_left: *Pair.left?
+			  * since: date
+			`),
+		},
+		{
+			name: "SQLite",
+			args: args{
+				src: TrimIndent(t, `
+				CREATE TABLE Tag(
+					id INTEGER PRIMARY KEY,
+					name,
+					score REAL
+				) STRICT, WITHOUT ROWID;
+				`),
+				dialect: SQLite,
+			},
+			want: TrimIndent(t, `
+			* table: Tag
+			  * !strict
+			  * !without_rowid
+			  * @id
+			  * name: any?
+			  * score: float?
+			`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := ParseSQL(strings.NewReader(tt.args.src), tt.args.dialect)
+			assert.NoError(t, err)
+			var out bytes.Buffer
+			assert.NoError(t, DumpMarkdown(&out, tables))
+			assert.Equal(t, tt.want+"\n", out.String())
+		})
+	}
+}
+
+func TestParseSQL_Error(t *testing.T) {
+	_, err := ParseSQL(strings.NewReader("CREATE TABLE 'users(id INTEGER);"), nil)
+	assert.Error(t, err)
+}
+
+func TestParseSQL_RoundTrip(t *testing.T) {
+	src := TrimIndent(t, `
+	* table: User
+	  * @id
+	  * $name: string
+	  * age: integer?
+	  * tags: *Tag.id[]
+
+	* table: Tag
+	  * @id
+	  * owner: *User.id?
+
+	* table: Member
+	  * @user: *User.id
+	  * @tag: *Tag.id
+	  * memo: string
+	`)
+	for _, d := range []Dialect{PostgreSQL, MySQL, SQLite, SQLServer, Oracle, Oracle11g, DuckDB, Spanner} {
+		t.Run(d.String(), func(t *testing.T) {
+			var ddl bytes.Buffer
+			assert.NoError(t, DumpSQL(&ddl, parseForTest(t, src), d, WithSourceOrder()))
+			tables, err := ParseSQL(&ddl, d)
+			assert.NoError(t, err)
+			var out bytes.Buffer
+			assert.NoError(t, DumpMarkdown(&out, tables))
+			want, got := src+"\n", out.String()
+			if d == Oracle || d == Oracle11g {
+				// Oracle upper-cases identifiers
+				want, got = strings.ToUpper(want), strings.ToUpper(got)
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
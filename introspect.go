@@ -0,0 +1,305 @@
+package md2sql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Introspect reads the tables of the database and converts them into the Markdown model like ParseSQL.
+// SQLite, PostgreSQL (current schema) and MySQL (current database) are supported.
+// Table comments become the descriptions of the tables.
+func Introspect(db *sql.DB, d Dialect) ([]*Table, error) {
+	if d == nil {
+		d = PostgreSQL
+	}
+	p := &ddlParser{d: d}
+	base := d
+	if md, ok := d.(mappedDialect); ok {
+		// types are converted by the type mapping, but the database is decided by the dialect
+		base = md.Dialect
+	}
+	var err error
+	switch base {
+	case SQLite:
+		err = p.introspectSQLite(db)
+	case PostgreSQL:
+		err = p.introspectInformationSchema(db, postgresQueries)
+	case MySQL:
+		err = p.introspectInformationSchema(db, mysqlQueries)
+	default:
+		return nil, fmt.Errorf("%s doesn't support introspection", base)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.result(), nil
+}
+
+// queryRows runs the query and calls fn for each row with the columns scanned into dest.
+func queryRows(db *sql.DB, query string, args []any, fn func() error, dest ...any) error {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (p *ddlParser) introspectSQLite(db *sql.DB) error {
+	var name, createSQL string
+	err := queryRows(db, "SELECT name, sql FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY rowid", nil, func() error {
+		t := &ddlTable{name: name}
+		// STRICT and WITHOUT ROWID are only in the statement
+		tokens, err := tokenizeSQL(createSQL)
+		if err != nil {
+			return err
+		}
+		r := &tokenReader{tokens: tokens}
+		for !r.eof() && !r.peek().isSymbol("(") {
+			r.next()
+		}
+		r.group()
+		for !r.eof() {
+			if r.accept("STRICT") {
+				t.annotate("strict")
+			} else if r.accept("WITHOUT", "ROWID") {
+				t.annotate("without_rowid")
+			} else {
+				r.next()
+			}
+		}
+		p.tables = append(p.tables, t)
+		return nil
+	}, &name, &createSQL)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range p.tables {
+		var pks []string
+		var column, sqlType string
+		var notNull, pk int
+		var defaultValue sql.NullString
+		err := queryRows(db, "SELECT name, type, \"notnull\", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid", []any{t.name}, func() error {
+			t.columns = append(t.columns, &ddlColumn{name: column, sqlType: sqlType, notNull: notNull != 0})
+			if pk > 0 {
+				for len(pks) < pk {
+					pks = append(pks, "")
+				}
+				pks[pk-1] = column
+			}
+			return nil
+		}, &column, &sqlType, &notNull, &defaultValue, &pk)
+		if err != nil {
+			return err
+		}
+		t.primaryKey = pks
+		// INTEGER PRIMARY KEY is the alias of rowid
+		if _, withoutRowid := t.annotations["without_rowid"]; len(pks) == 1 && !withoutRowid {
+			if c := t.column(pks[0]); c != nil && strings.EqualFold(c.sqlType, "INTEGER") {
+				c.autoIncrement = true
+			}
+		}
+
+		var id, seq int
+		var refTable string
+		var from string
+		var to sql.NullString
+		fks := make(map[int]*foreignKey)
+		err = queryRows(db, "SELECT id, seq, \"table\", \"from\", \"to\" FROM pragma_foreign_key_list(?) ORDER BY id, seq", []any{t.name}, func() error {
+			fk, ok := fks[id]
+			if !ok {
+				fk = &foreignKey{Table: t.name, RefTable: refTable}
+				fks[id] = fk
+				t.fks = append(t.fks, fk)
+			}
+			fk.Columns = append(fk.Columns, from)
+			if to.Valid {
+				fk.RefColumns = append(fk.RefColumns, to.String)
+			}
+			return nil
+		}, &id, &seq, &refTable, &from, &to)
+		if err != nil {
+			return err
+		}
+
+		var indexes []string
+		var index string
+		var unique int
+		err = queryRows(db, "SELECT name, \"unique\" FROM pragma_index_list(?)", []any{t.name}, func() error {
+			if unique != 0 {
+				indexes = append(indexes, index)
+			}
+			return nil
+		}, &index, &unique)
+		if err != nil {
+			return err
+		}
+		for _, index := range indexes {
+			var columns []string
+			err := queryRows(db, "SELECT name FROM pragma_index_info(?) ORDER BY seqno", []any{index}, func() error {
+				columns = append(columns, column)
+				return nil
+			}, &column)
+			if err != nil {
+				return err
+			}
+			t.uniques = append(t.uniques, columns)
+		}
+	}
+	return nil
+}
+
+// schemaQueries are the queries of information_schema. Each query returns rows of the current schema.
+type schemaQueries struct {
+	// tables returns table_name and the comment.
+	tables string
+	// columns returns table_name, column_name, the SQL type, is_nullable ("YES" or "NO") and the marker of auto-increment keys.
+	columns string
+	// constraints returns table_name, constraint_name, constraint_type, column_name, the referred table and column.
+	constraints string
+	// indexes returns table_name and CREATE INDEX statement of the indexes that don't back the constraints
+	// if the database has unique indexes that aren't constraints.
+	indexes string
+}
+
+var postgresQueries = schemaQueries{
+	tables: `SELECT table_name, COALESCE(obj_description(format('%I.%I', table_schema, table_name)::regclass, 'pg_class'), '')
+FROM information_schema.tables
+WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+ORDER BY table_name`,
+	columns: `SELECT table_name, column_name,
+	CASE
+		WHEN data_type IN ('USER-DEFINED', 'ARRAY') THEN udt_name
+		WHEN character_maximum_length IS NOT NULL THEN data_type || '(' || character_maximum_length || ')'
+		WHEN data_type = 'numeric' AND numeric_precision IS NOT NULL THEN data_type || '(' || numeric_precision || ',' || numeric_scale || ')'
+		ELSE data_type
+	END,
+	is_nullable,
+	CASE WHEN is_identity = 'YES' THEN 'IDENTITY' ELSE COALESCE(column_default, '') END
+FROM information_schema.columns
+WHERE table_schema = current_schema()
+ORDER BY table_name, ordinal_position`,
+	constraints: `SELECT kcu.table_name, kcu.constraint_name, tc.constraint_type, kcu.column_name,
+	COALESCE(rk.table_name, ''), COALESCE(rk.column_name, '')
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu
+	ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name AND kcu.table_name = tc.table_name
+LEFT JOIN information_schema.referential_constraints rc
+	ON rc.constraint_schema = tc.constraint_schema AND rc.constraint_name = tc.constraint_name
+LEFT JOIN information_schema.key_column_usage rk
+	ON rk.constraint_schema = rc.unique_constraint_schema AND rk.constraint_name = rc.unique_constraint_name
+	AND rk.ordinal_position = kcu.position_in_unique_constraint
+WHERE tc.table_schema = current_schema() AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE', 'FOREIGN KEY')
+ORDER BY kcu.table_name, kcu.constraint_name, kcu.ordinal_position`,
+	indexes: `SELECT tablename, indexdef
+FROM pg_indexes i
+WHERE schemaname = current_schema() AND NOT EXISTS (
+	SELECT 1 FROM pg_constraint
+	WHERE conindid = format('%I.%I', i.schemaname, i.indexname)::regclass AND contype IN ('p', 'u', 'x')
+)`,
+}
+
+var mysqlQueries = schemaQueries{
+	tables: `SELECT table_name, table_comment
+FROM information_schema.tables
+WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'
+ORDER BY table_name`,
+	columns: `SELECT table_name, column_name, column_type, is_nullable, extra
+FROM information_schema.columns
+WHERE table_schema = DATABASE()
+ORDER BY table_name, ordinal_position`,
+	constraints: `SELECT kcu.table_name, kcu.constraint_name, tc.constraint_type, kcu.column_name,
+	COALESCE(kcu.referenced_table_name, ''), COALESCE(kcu.referenced_column_name, '')
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu
+	ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name AND kcu.table_name = tc.table_name
+WHERE tc.table_schema = DATABASE() AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE', 'FOREIGN KEY')
+ORDER BY kcu.table_name, kcu.constraint_name, kcu.ordinal_position`,
+}
+
+func (p *ddlParser) introspectInformationSchema(db *sql.DB, q schemaQueries) error {
+	var table, comment string
+	err := queryRows(db, q.tables, nil, func() error {
+		p.tables = append(p.tables, &ddlTable{name: table, description: comment})
+		return nil
+	}, &table, &comment)
+	if err != nil {
+		return err
+	}
+
+	var column, sqlType, nullable, marker string
+	err = queryRows(db, q.columns, nil, func() error {
+		if t := p.table(table); t != nil {
+			c := &ddlColumn{name: column, sqlType: sqlType, notNull: nullable == "NO"}
+			tokens, _ := tokenizeSQL(marker)
+			for _, token := range tokens {
+				if isKeyword(token, autoIncrementMarkers) {
+					c.autoIncrement = true
+				}
+			}
+			t.columns = append(t.columns, c)
+		}
+		return nil
+	}, &table, &column, &sqlType, &nullable, &marker)
+	if err != nil {
+		return err
+	}
+
+	var name, constraintType, refTable, refColumn string
+	var lastTable, lastName string
+	err = queryRows(db, q.constraints, nil, func() error {
+		t := p.table(table)
+		if t == nil {
+			return nil
+		}
+		// rows are ordered by the constraint, so a new name starts the next constraint
+		first := table != lastTable || name != lastName
+		lastTable, lastName = table, name
+		switch constraintType {
+		case "PRIMARY KEY":
+			t.primaryKey = append(t.primaryKey, column)
+		case "UNIQUE":
+			if first {
+				t.uniques = append(t.uniques, nil)
+			}
+			t.uniques[len(t.uniques)-1] = append(t.uniques[len(t.uniques)-1], column)
+		case "FOREIGN KEY":
+			if first {
+				t.fks = append(t.fks, &foreignKey{Table: table, RefTable: refTable})
+			}
+			fk := t.fks[len(t.fks)-1]
+			fk.Columns = append(fk.Columns, column)
+			fk.RefColumns = append(fk.RefColumns, refColumn)
+		}
+		return nil
+	}, &table, &name, &constraintType, &column, &refTable, &refColumn)
+	if err != nil {
+		return err
+	}
+
+	if q.indexes != "" {
+		var definition string
+		err = queryRows(db, q.indexes, nil, func() error {
+			tokens, err := tokenizeSQL(definition)
+			if err != nil {
+				return err
+			}
+			r := &tokenReader{tokens: tokens}
+			if r.accept("CREATE", "UNIQUE", "INDEX") {
+				p.createIndex(r, true)
+			}
+			return nil
+		}, &table, &definition)
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package md2sql
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntrospect_SQLite(t *testing.T) {
+	src := TrimIndent(t, `
+	* table: User
+	  * @id
+	  * $name: string
+	  * age: integer?
+	  * price: decimal
+	  * tags: *Tag.id[]
+
+	* table: Tag
+	  * !strict
+	  * @id
+	  * owner: *User.id?
+
+	* table: Member
+	  * !without_rowid
+	  * @user: *User.id
+	  * @tag: *Tag.id
+	  * memo: string
+	`)
+	var ddl bytes.Buffer
+	assert.NoError(t, DumpSQL(&ddl, parseForTest(t, src), SQLite, WithSourceOrder()))
+	db := openSQLite(t)
+	execSQLite(t, db, ddl.String())
+
+	tables, err := Introspect(db, SQLite)
+	assert.NoError(t, err)
+	var out bytes.Buffer
+	assert.NoError(t, DumpMarkdown(&out, tables))
+	assert.Equal(t, src+"\n", out.String())
+}
+
+func TestIntrospect_Unsupported(t *testing.T) {
+	_, err := Introspect(openSQLite(t), Oracle)
+	assert.Error(t, err)
+}
+
+func TestIntrospect_SQLiteUniqueConstraint(t *testing.T) {
+	db := openSQLite(t)
+	execSQLite(t, db, `CREATE TABLE User (id INTEGER PRIMARY KEY, name TEXT NOT NULL, email TEXT NOT NULL, UNIQUE (email));
+CREATE UNIQUE INDEX user_name ON User (name);
+`)
+	tables, err := Introspect(db, SQLite)
+	assert.NoError(t, err)
+	var out bytes.Buffer
+	assert.NoError(t, DumpMarkdown(&out, tables))
+	assert.Equal(t, TrimIndent(t, `
+	* table: User
+	  * @id
+	  * $name: string
+	  * $email: string
+	`)+"\n", out.String())
+}
+
+// TestIntrospect_InformationSchema replaces information_schema with SQLite tables that have the rows of the queries.
+func TestIntrospect_InformationSchema(t *testing.T) {
+	db := openSQLite(t)
+	execSQLite(t, db, `CREATE TABLE t (table_name, comment);
+INSERT INTO t VALUES ('users', 'members');
+CREATE TABLE c (table_name, column_name, sql_type, is_nullable, marker);
+INSERT INTO c VALUES ('users', 'id', 'integer', 'NO', 'IDENTITY'), ('users', 'email', 'text', 'NO', ''), ('users', 'name', 'text', 'NO', '');
+CREATE TABLE k (table_name, constraint_name, constraint_type, column_name, ref_table, ref_column);
+INSERT INTO k VALUES ('users', 'users_pkey', 'PRIMARY KEY', 'id', '', ''), ('users', 'users_email_key', 'UNIQUE', 'email', '', '');
+CREATE TABLE i (table_name, definition);
+INSERT INTO i VALUES ('users', 'CREATE UNIQUE INDEX users_name ON public.users USING btree (name)');
+`)
+	p := &ddlParser{d: PostgreSQL}
+	assert.NoError(t, p.introspectInformationSchema(db, schemaQueries{
+		tables:      "SELECT * FROM t",
+		columns:     "SELECT * FROM c",
+		constraints: "SELECT * FROM k",
+		indexes:     "SELECT * FROM i",
+	}))
+	var out bytes.Buffer
+	assert.NoError(t, DumpMarkdown(&out, p.result()))
+	assert.Equal(t, TrimIndent(t, `
+	* table: users
+	  members
+	  * @id
+	  * $email: string
+	  * $name: string
+	`)+"\n", out.String())
+}
@@ -0,0 +1,236 @@
+package md2sql
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// liquibaseAuthor is the author of the changeSets. The pair of the ID and the author identifies the changeSet.
+const liquibaseAuthor = "md2sql"
+
+type liquibaseChangeLog struct {
+	XMLName           xml.Name              `xml:"databaseChangeLog" yaml:"-"`
+	Xmlns             string                `xml:"xmlns,attr" yaml:"-"`
+	XmlnsXsi          string                `xml:"xmlns:xsi,attr" yaml:"-"`
+	SchemaLocation    string                `xml:"xsi:schemaLocation,attr" yaml:"-"`
+	DatabaseChangeLog []*liquibaseChangeSet `xml:"changeSet" yaml:"databaseChangeLog"`
+}
+
+// liquibaseChangeSet has one change. YAML has the change in the list of "changes" and XML has it as the child.
+type liquibaseChangeSet struct {
+	ID              string            `xml:"id,attr" yaml:"id"`
+	Author          string            `xml:"author,attr" yaml:"author"`
+	Changes         []liquibaseChange `xml:"-" yaml:"changes"`
+	liquibaseChange `yaml:"-"`
+}
+
+func (c *liquibaseChangeSet) MarshalYAML() (any, error) {
+	type changeSet liquibaseChangeSet
+	return map[string]*changeSet{"changeSet": (*changeSet)(c)}, nil
+}
+
+type liquibaseChange struct {
+	CreateTable             *liquibaseCreateTable   `xml:"createTable,omitempty" yaml:"createTable,omitempty"`
+	CreateIndex             *liquibaseCreateIndex   `xml:"createIndex,omitempty" yaml:"createIndex,omitempty"`
+	AddForeignKeyConstraint *liquibaseAddForeignKey `xml:"addForeignKeyConstraint,omitempty" yaml:"addForeignKeyConstraint,omitempty"`
+}
+
+type liquibaseCreateTable struct {
+	TableName string             `xml:"tableName,attr" yaml:"tableName"`
+	Remarks   string             `xml:"remarks,attr,omitempty" yaml:"remarks,omitempty"`
+	Columns   []*liquibaseColumn `xml:"column" yaml:"columns"`
+}
+
+type liquibaseColumn struct {
+	Name                 string                `xml:"name,attr" yaml:"name"`
+	Type                 string                `xml:"type,attr,omitempty" yaml:"type,omitempty"`
+	AutoIncrement        bool                  `xml:"autoIncrement,attr,omitempty" yaml:"autoIncrement,omitempty"`
+	DefaultValueComputed string                `xml:"defaultValueComputed,attr,omitempty" yaml:"defaultValueComputed,omitempty"`
+	Constraints          *liquibaseConstraints `xml:"constraints,omitempty" yaml:"constraints,omitempty"`
+}
+
+// MarshalYAML writes the column in "- column:" style of Liquibase.
+func (c *liquibaseColumn) MarshalYAML() (any, error) {
+	type column liquibaseColumn
+	return map[string]*column{"column": (*column)(c)}, nil
+}
+
+type liquibaseConstraints struct {
+	PrimaryKey     bool   `xml:"primaryKey,attr,omitempty" yaml:"primaryKey,omitempty"`
+	PrimaryKeyName string `xml:"primaryKeyName,attr,omitempty" yaml:"primaryKeyName,omitempty"`
+	Nullable       *bool  `xml:"nullable,attr,omitempty" yaml:"nullable,omitempty"`
+}
+
+type liquibaseCreateIndex struct {
+	TableName string             `xml:"tableName,attr" yaml:"tableName"`
+	IndexName string             `xml:"indexName,attr" yaml:"indexName"`
+	Unique    bool               `xml:"unique,attr" yaml:"unique"`
+	Columns   []*liquibaseColumn `xml:"column" yaml:"columns"`
+}
+
+type liquibaseAddForeignKey struct {
+	BaseTableName         string `xml:"baseTableName,attr" yaml:"baseTableName"`
+	BaseColumnNames       string `xml:"baseColumnNames,attr" yaml:"baseColumnNames"`
+	ConstraintName        string `xml:"constraintName,attr" yaml:"constraintName"`
+	ReferencedTableName   string `xml:"referencedTableName,attr" yaml:"referencedTableName"`
+	ReferencedColumnNames string `xml:"referencedColumnNames,attr" yaml:"referencedColumnNames"`
+}
+
+// DumpLiquibase writes the tables as the Liquibase changelog in YAML.
+// Each changeSet has one createTable, createIndex or addForeignKeyConstraint change,
+// and its ID is made from the names of the table and the constraint so that regenerating keeps the IDs.
+// Foreign keys are added after all tables are created.
+func DumpLiquibase(w io.Writer, tables []*Table, d Dialect, opts ...Option) error {
+	changeLog, err := liquibaseChanges(tables, d, opts)
+	if err != nil {
+		return err
+	}
+	e := yaml.NewEncoder(w)
+	e.SetIndent(2)
+	if err := e.Encode(changeLog); err != nil {
+		return err
+	}
+	return e.Close()
+}
+
+// DumpLiquibaseXML writes the tables as the Liquibase changelog in XML. See DumpLiquibase.
+func DumpLiquibaseXML(w io.Writer, tables []*Table, d Dialect, opts ...Option) error {
+	changeLog, err := liquibaseChanges(tables, d, opts)
+	if err != nil {
+		return err
+	}
+	changeLog.Xmlns = "http://www.liquibase.org/xml/ns/dbchangelog"
+	changeLog.XmlnsXsi = "http://www.w3.org/2001/XMLSchema-instance"
+	changeLog.SchemaLocation = "http://www.liquibase.org/xml/ns/dbchangelog http://www.liquibase.org/xml/ns/dbchangelog/dbchangelog-latest.xsd"
+	io.WriteString(w, xml.Header)
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(changeLog); err != nil {
+		return err
+	}
+	io.WriteString(w, "\n")
+	return nil
+}
+
+func liquibaseChanges(tables []*Table, d Dialect, opts []Option) (*liquibaseChangeLog, error) {
+	o := newOption(opts)
+	md := o.dialect(d)
+	if _, err := fixRelations(tables); err != nil {
+		return nil, err
+	}
+	caps := md.Capabilities()
+	result := &liquibaseChangeLog{}
+	add := func(id string, change liquibaseChange) {
+		result.DatabaseChangeLog = append(result.DatabaseChangeLog, &liquibaseChangeSet{
+			ID:              id,
+			Author:          liquibaseAuthor,
+			Changes:         []liquibaseChange{change},
+			liquibaseChange: change,
+		})
+	}
+	notNull := func(c *liquibaseColumn) *liquibaseColumn {
+		nullable := false
+		if c.Constraints == nil {
+			c.Constraints = &liquibaseConstraints{}
+		}
+		c.Constraints.Nullable = &nullable
+		return c
+	}
+	keyColumn := func(table string, c *Column, pks []string) *liquibaseColumn {
+		result := &liquibaseColumn{Name: c.Name, Type: md.baseType(c), Constraints: &liquibaseConstraints{
+			PrimaryKey:     true,
+			PrimaryKeyName: o.naming.primaryKey(md.Dialect, table, pks),
+		}}
+		if c.AutoIncrement {
+			if a, ok := md.identityOf(c); ok && strings.HasPrefix(strings.ToUpper(a.Modifier), "DEFAULT ") {
+				result.DefaultValueComputed = strings.TrimSpace(a.Modifier[len("DEFAULT "):])
+			} else {
+				result.AutoIncrement = true
+			}
+		}
+		return notNull(result)
+	}
+	var fks []*foreignKey
+	createIndex := func(table, column string) {
+		if caps.NoUniqueIndex {
+			return
+		}
+		name := expandName(md.Dialect, firstTemplate(o.naming.Unique, md.ConstraintNaming().Unique, StandardNamingConvention.Unique), table, []string{column}, "", nil)
+		add("createIndex-"+name, liquibaseChange{CreateIndex: &liquibaseCreateIndex{
+			TableName: table,
+			IndexName: name,
+			Unique:    true,
+			Columns:   []*liquibaseColumn{{Name: column}},
+		}})
+	}
+
+	for _, t := range tables {
+		pks := primaryKeys([]*Table{t})[t.Name]
+		create := &liquibaseCreateTable{TableName: t.Name, Remarks: t.Description}
+		for _, c := range t.Columns {
+			switch {
+			case c.AssociativeEntity:
+				continue
+			case c.PrimaryKey:
+				create.Columns = append(create.Columns, keyColumn(t.Name, c, pks))
+			case c.LinkTable != "":
+				create.Columns = append(create.Columns, &liquibaseColumn{Name: c.Name, Type: md.baseType(c)})
+			default:
+				create.Columns = append(create.Columns, &liquibaseColumn{Name: c.Name, Type: md.columnType(c)})
+			}
+			if !c.Nullable && !c.PrimaryKey {
+				notNull(create.Columns[len(create.Columns)-1])
+			}
+		}
+		add("createTable-"+t.Name, liquibaseChange{CreateTable: create})
+		for _, c := range t.Columns {
+			if c.Index {
+				createIndex(t.Name, c.Name)
+			}
+		}
+		fks = append(fks, foreignKeys(t)...)
+	}
+
+	// associative entity
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if c.LinkTable == "" || !c.AssociativeEntity {
+				continue
+			}
+			name := t.Name + "_" + c.Name
+			create := &liquibaseCreateTable{TableName: name}
+			create.Columns = append(create.Columns, keyColumn(name, &Column{Name: "id", PrimaryKey: true, AutoIncrement: true}, []string{"id"}))
+			var columns, pks []string
+			for _, pk := range t.Columns {
+				if pk.PrimaryKey {
+					pks = append(pks, pk.Name)
+					columns = append(columns, t.Name+"_"+pk.Name)
+					create.Columns = append(create.Columns, notNull(&liquibaseColumn{Name: t.Name + "_" + pk.Name, Type: md.baseType(pk)}))
+				}
+			}
+			link := c.LinkTable + "_" + c.LinkColumn
+			create.Columns = append(create.Columns, notNull(&liquibaseColumn{Name: link, Type: md.baseType(c)}))
+			add("createTable-"+name, liquibaseChange{CreateTable: create})
+			fks = append(fks,
+				&foreignKey{Table: name, Columns: columns, RefTable: t.Name, RefColumns: pks},
+				&foreignKey{Table: name, Columns: []string{link}, RefTable: c.LinkTable, RefColumns: []string{c.LinkColumn}})
+		}
+	}
+
+	if !caps.NoForeignKey {
+		for _, fk := range fks {
+			name := expandName(md.Dialect, firstTemplate(o.naming.ForeignKey, md.ConstraintNaming().ForeignKey, StandardNamingConvention.ForeignKey), fk.Table, fk.Columns, fk.RefTable, fk.RefColumns)
+			add("addForeignKeyConstraint-"+name, liquibaseChange{AddForeignKeyConstraint: &liquibaseAddForeignKey{
+				BaseTableName:         fk.Table,
+				BaseColumnNames:       strings.Join(fk.Columns, ", "),
+				ConstraintName:        name,
+				ReferencedTableName:   fk.RefTable,
+				ReferencedColumnNames: strings.Join(fk.RefColumns, ", "),
+			}})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package md2sql
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpLiquibase(t *testing.T) {
+	tables := parseForTest(t, TrimIndent(t, `
+	* table: User
+	  Users who signed up.
+	  * @id
+	  * $email: varchar(100)
+	  * job: *Job.code?
+	* table: Job
+	  * @code: char(4)
+	`))
+	var out bytes.Buffer
+	assert.NoError(t, DumpLiquibase(&out, tables, PostgreSQL, WithNamingConvention(StandardNamingConvention)))
+	assert.Equal(t, TrimIndent(t, `
+	databaseChangeLog:
+	  - changeSet:
+	      id: createTable-User
+	      author: md2sql
+	      changes:
+	        - createTable:
+	            tableName: User
+	            remarks: Users who signed up.
+	            columns:
+	              - column:
+	                  name: id
+	                  type: INTEGER
+	                  autoIncrement: true
+	                  constraints:
+	                    primaryKey: true
+	                    primaryKeyName: pk_User
+	                    nullable: false
+	              - column:
+	                  name: email
+	                  type: VARCHAR(100)
+	                  constraints:
+	                    nullable: false
+	              - column:
+	                  name: job
+	                  type: CHAR(4)
+	  - changeSet:
+	      id: createIndex-uq_User_email
+	      author: md2sql
+	      changes:
+	        - createIndex:
+	            tableName: User
+	            indexName: uq_User_email
+	            unique: true
+	            columns:
+	              - column:
+	                  name: email
+	  - changeSet:
+	      id: createTable-Job
+	      author: md2sql
+	      changes:
+	        - createTable:
+	            tableName: Job
+	            columns:
+	              - column:
+	                  name: code
+	                  type: CHAR(4)
+	                  constraints:
+	                    primaryKey: true
+	                    primaryKeyName: pk_Job
+	                    nullable: false
+	  - changeSet:
+	      id: addForeignKeyConstraint-fk_User_job_Job
+	      author: md2sql
+	      changes:
+	        - addForeignKeyConstraint:
+	            baseTableName: User
+	            baseColumnNames: job
+	            constraintName: fk_User_job_Job
+	            referencedTableName: Job
+	            referencedColumnNames: code
+	`)+"\n", out.String())
+}
+
+func TestDumpLiquibaseXML(t *testing.T) {
+	tables := parseForTest(t, TrimIndent(t, `
+	* table: User
+	  * @id
+	  * tags: *Tag.id[]
+	* table: Tag
+	  * @id
+	`))
+	var out bytes.Buffer
+	assert.NoError(t, DumpLiquibaseXML(&out, tables, PostgreSQL, WithIdentity("uuid")))
+	assert.Equal(t, TrimIndent(t, `
+	<?xml version="1.0" encoding="UTF-8"?>
+	<databaseChangeLog xmlns="http://www.liquibase.org/xml/ns/dbchangelog" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://www.liquibase.org/xml/ns/dbchangelog http://www.liquibase.org/xml/ns/dbchangelog/dbchangelog-latest.xsd">
+	  <changeSet id="createTable-User" author="md2sql">
+	    <createTable tableName="User">
+	      <column name="id" type="UUID" defaultValueComputed="gen_random_uuid()">
+	        <constraints primaryKey="true" nullable="false"></constraints>
+	      </column>
+	    </createTable>
+	  </changeSet>
+	  <changeSet id="createTable-Tag" author="md2sql">
+	    <createTable tableName="Tag">
+	      <column name="id" type="UUID" defaultValueComputed="gen_random_uuid()">
+	        <constraints primaryKey="true" nullable="false"></constraints>
+	      </column>
+	    </createTable>
+	  </changeSet>
+	  <changeSet id="createTable-User_tags" author="md2sql">
+	    <createTable tableName="User_tags">
+	      <column name="id" type="UUID" defaultValueComputed="gen_random_uuid()">
+	        <constraints primaryKey="true" nullable="false"></constraints>
+	      </column>
+	      <column name="User_id" type="UUID">
+	        <constraints nullable="false"></constraints>
+	      </column>
+	      <column name="Tag_id" type="UUID">
+	        <constraints nullable="false"></constraints>
+	      </column>
+	    </createTable>
+	  </changeSet>
+	  <changeSet id="addForeignKeyConstraint-User_tags_User_id_fkey" author="md2sql">
+	    <addForeignKeyConstraint baseTableName="User_tags" baseColumnNames="User_id" constraintName="User_tags_User_id_fkey" referencedTableName="User" referencedColumnNames="id"></addForeignKeyConstraint>
+	  </changeSet>
+	  <changeSet id="addForeignKeyConstraint-User_tags_Tag_id_fkey" author="md2sql">
+	    <addForeignKeyConstraint baseTableName="User_tags" baseColumnNames="Tag_id" constraintName="User_tags_Tag_id_fkey" referencedTableName="Tag" referencedColumnNames="id"></addForeignKeyConstraint>
+	  </changeSet>
+	</databaseChangeLog>
+	`)+"\n", out.String())
+}
@@ -0,0 +1,76 @@
+package md2sql
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+var tableType2label = map[TableType]string{
+	EntityTable:       "table",
+	MasterTable:       "master",
+	TransactionTable:  "tran",
+	WorkTable:         "work",
+	SummaryTable:      "summary",
+	View:              "view",
+	AssociativeEntity: "associativeentity",
+}
+
+// DumpMarkdown writes the tables in the list syntax of md2sql. Parse reads it back.
+func DumpMarkdown(w io.Writer, tables []*Table) error {
+	for i, t := range tables {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		var prefix string
+		if !t.Independent {
+			prefix = "-"
+		}
+		fmt.Fprintf(w, "* %s%s: %s\n", prefix, tableType2label[t.Type], t.Name)
+		if t.Description != "" {
+			for _, line := range strings.Split(t.Description, "\n") {
+				fmt.Fprintf(w, "  %s\n", strings.TrimSpace(line))
+			}
+		}
+		keys := make([]string, 0, len(t.Annotations))
+		for key := range t.Annotations {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if value := t.Annotations[key]; value != "" {
+				fmt.Fprintf(w, "  * !%s: %s\n", key, value)
+			} else {
+				fmt.Fprintf(w, "  * !%s\n", key)
+			}
+		}
+		for _, c := range t.Columns {
+			fmt.Fprintf(w, "  * %s\n", markdownColumn(c))
+		}
+	}
+	return nil
+}
+
+// markdownColumn returns the column like "$email: string" or "job: *Job.id?".
+func markdownColumn(c *Column) string {
+	var prefix string
+	if c.PrimaryKey {
+		prefix = "@"
+	} else if c.Index {
+		prefix = "$"
+	}
+	if c.PrimaryKey && c.AutoIncrement && c.LinkTable == "" {
+		return prefix + c.Name
+	}
+	var suffix string
+	if c.AssociativeEntity {
+		suffix = "[]"
+	} else if c.Nullable && !c.PrimaryKey {
+		suffix = "?"
+	}
+	if c.LinkTable != "" {
+		return fmt.Sprintf("%s%s: *%s.%s%s", prefix, c.Name, c.LinkTable, c.LinkColumn, suffix)
+	}
+	return fmt.Sprintf("%s%s: %s%s", prefix, c.Name, c.Type, suffix)
+}
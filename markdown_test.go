@@ -0,0 +1,37 @@
+package md2sql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpMarkdown(t *testing.T) {
+	src := TrimIndent(t, `
+	* master: User
+	  User accounts
+	  of the service.
+	  * !engine: ReplacingMergeTree(version)
+	  * !strict
+	  * @id
+	  * $email: varchar(100)
+	  * age: integer?
+	  * job: *Job.id?
+	  * tags: *Tag.id[]
+
+	* tran: Job
+	  * @code: char(8)
+	  * $owner: *User.id
+
+	* -work: Member
+	  * @user: *User.id
+	  * @job: *Job.code
+	`)
+	tables, err := Parse(strings.NewReader(src))
+	assert.NoError(t, err)
+	var out bytes.Buffer
+	assert.NoError(t, DumpMarkdown(&out, tables))
+	assert.Equal(t, src+"\n", out.String())
+}
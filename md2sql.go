@@ -36,23 +36,31 @@ var label2tableType = map[string]TableType{
 }
 
 type Table struct {
-	Type        TableType
-	Independent bool
-	Name        string
-	Columns     []*Column
+	Type        TableType `json:"type"`
+	Independent bool      `json:"independent"`
+	Name        string    `json:"name"`
+	Columns     []*Column `json:"columns"`
+	// Annotations are dialect specific table options written as "!key: value" items like "!engine: MergeTree".
+	// Keys are lower-cased.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Description is written in the lines after the label like the comment of the table.
+	Description string `json:"description,omitempty"`
 }
 
 type Column struct {
-	Name                 string
-	Type                 string
-	LinkTable            string
-	LinkColumn           string
-	PrimaryKey           bool
-	AutoIncrement        bool
-	Index                bool
-	Nullable             bool
-	AssociativeEntity    bool
-	ForeignKeyConstraint bool
+	Name                 string `json:"name"`
+	Type                 string `json:"type,omitempty"`
+	LinkTable            string `json:"linkTable,omitempty"`
+	LinkColumn           string `json:"linkColumn,omitempty"`
+	PrimaryKey           bool   `json:"primaryKey,omitempty"`
+	AutoIncrement        bool   `json:"autoIncrement,omitempty"`
+	Index                bool   `json:"index,omitempty"`
+	Nullable             bool   `json:"nullable,omitempty"`
+	AssociativeEntity    bool   `json:"associativeEntity,omitempty"`
+	ForeignKeyConstraint bool   `json:"foreignKeyConstraint,omitempty"`
+	// Identity is the identity strategy of the auto-increment key ("!identity" annotation of the table).
+	// Foreign key columns have the strategy of the referred key. It is filled by fixRelations.
+	Identity string `json:"identity,omitempty"`
 }
 
 func ParseColumn(src string) (*Column, error) {
@@ -102,19 +110,48 @@ func ParseColumn(src string) (*Column, error) {
 	return &result, nil
 }
 
+// parseAnnotation parses a table annotation like "!engine: ReplacingMergeTree(version)".
+func parseAnnotation(src string) (key, value string, ok bool) {
+	src = strings.TrimSpace(src)
+	if !strings.HasPrefix(src, "!") {
+		return "", "", false
+	}
+	key, value, _ = strings.Cut(strings.TrimPrefix(src, "!"), ":")
+	return strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value), true
+}
+
+// annotationFlag returns true if the annotation like "!strict" exists and its value isn't "false".
+func annotationFlag(annotations map[string]string, key string) bool {
+	v, ok := annotations[key]
+	return ok && !strings.EqualFold(v, "false")
+}
+
 func Parse(r io.Reader) ([]*Table, error) {
-	markdown := goldmark.New()
 	b, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
+	_, body := splitFrontMatter(b)
+	return parse(body)
+}
+
+func parse(b []byte) ([]*Table, error) {
+	markdown := goldmark.New()
 	reader := text.NewReader(b)
 	n := markdown.Parser().Parse(reader)
 	var tables []*Table
-	err = ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+	err := ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
 		if n.Kind() == ast.KindListItem && entering {
 			if n.ChildCount() == 2 && n.LastChild().Kind() == ast.KindList { // nested
 				label := string(n.FirstChild().Text(b))
+				var description []string
+				if lines := n.FirstChild().Lines(); lines.Len() > 1 {
+					for i := 0; i < lines.Len(); i++ {
+						line := lines.At(i)
+						description = append(description, strings.TrimSpace(string(line.Value(b))))
+					}
+					label, description = description[0], description[1:]
+				}
 				if t, name, ok := strings.Cut(label, ":"); ok {
 					independent := true
 					if strings.HasPrefix(t, "_") || strings.HasPrefix(t, "-") {
@@ -123,9 +160,19 @@ func Parse(r io.Reader) ([]*Table, error) {
 					}
 					if tt, ok := label2tableType[strings.ToLower(t)]; ok {
 						var columns []*Column
+						var annotations map[string]string
 						c := n.LastChild().FirstChild()
 						for c != nil {
-							column, err := ParseColumn(string(c.FirstChild().Text(b)))
+							src := string(c.FirstChild().Text(b))
+							if key, value, ok := parseAnnotation(src); ok {
+								if annotations == nil {
+									annotations = make(map[string]string)
+								}
+								annotations[key] = value
+								c = c.NextSibling()
+								continue
+							}
+							column, err := ParseColumn(src)
 							if err != nil {
 								return ast.WalkStop, err
 							}
@@ -137,6 +184,8 @@ func Parse(r io.Reader) ([]*Table, error) {
 							Independent: independent,
 							Name:        strings.TrimSpace(name),
 							Columns:     columns,
+							Annotations: annotations,
+							Description: strings.Join(description, "\n"),
 						})
 					}
 				}
@@ -166,22 +215,55 @@ type Relation struct {
 	Label           string
 }
 
-func fixRelations(tables []*Table, d Dialect) ([]*Relation, error) {
-	tmap := make(map[string]*Table)
+// fixRelations fills the types and identity strategies of foreign key columns with those of the referred columns
+// and returns the relations. Empty type means the referred column is an auto-increment key.
+func fixRelations(tables []*Table) ([]*Relation, error) {
 	cmap := make(map[string]*Column)
 
 	key := func(table, column string) string {
 		return table + "/**/" + column
 	}
 	for _, t := range tables {
-		tmap[t.Name] = t
 		for _, c := range t.Columns {
 			cmap[key(t.Name, c.Name)] = c
 		}
 	}
 
-	var result []*Relation
+	// identity strategy of auto-increment keys
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if c.AutoIncrement {
+				c.Identity = t.Annotations["identity"]
+			}
+		}
+	}
+
 	// fill type
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if c.LinkTable == "" {
+				continue
+			}
+			c.Type = "INTEGER" // fill dummy
+			c.Identity = ""
+			// follow the chain of foreign keys
+			tc, ok := cmap[key(c.LinkTable, c.LinkColumn)]
+			for i := 0; ok && i < len(cmap); i++ {
+				if tc.LinkTable == "" {
+					c.Type = tc.Type
+					c.Identity = tc.Identity
+					break
+				}
+				tc, ok = cmap[key(tc.LinkTable, tc.LinkColumn)]
+			}
+		}
+	}
+
+	return buildRelations(tables), nil
+}
+
+func buildRelations(tables []*Table) []*Relation {
+	var result []*Relation
 	for _, t := range tables {
 		for _, c := range t.Columns {
 			if c.LinkTable != "" {
@@ -191,11 +273,6 @@ func fixRelations(tables []*Table, d Dialect) ([]*Relation, error) {
 					ToTable:         c.LinkTable,
 					Label:           c.Name,
 				}
-				if tc, ok := cmap[key(c.LinkTable, c.LinkColumn)]; ok {
-					c.Type = d.PrimaryKeyBaseType(tc.Type)
-				} else {
-					c.Type = "INTEGER" // fill dummy
-				}
 				if c.AssociativeEntity {
 					rel.ToCardinality = ZeroOrMore
 					rel.FromCardinality = ZeroOrMore
@@ -210,6 +287,5 @@ func fixRelations(tables []*Table, d Dialect) ([]*Relation, error) {
 			}
 		}
 	}
-
-	return result, nil
+	return result
 }
@@ -222,6 +222,32 @@ func TestParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "table annotations",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Event
+				  * !Engine: ReplacingMergeTree(version)
+				  * name: text
+				`),
+			},
+			want: []*Table{
+				{
+					Name:        "Event",
+					Type:        EntityTable,
+					Independent: true,
+					Columns: []*Column{
+						{
+							Name: "name",
+							Type: "text",
+						},
+					},
+					Annotations: map[string]string{
+						"engine": "ReplacingMergeTree(version)",
+					},
+				},
+			},
+		},
 		{
 			name: "master table",
 			args: args{
@@ -3,6 +3,7 @@ package md2sql
 import (
 	"fmt"
 	"io"
+	"strings"
 )
 
 var mermaidCN = map[Cardinality][]string{
@@ -11,8 +12,12 @@ var mermaidCN = map[Cardinality][]string{
 	ZeroOrMore: {"}o", "o{"},
 }
 
-func DumpMermaid(w io.Writer, tables []*Table, d Dialect) error {
-	relations, err := fixRelations(tables, d)
+// Mermaid doesn't allow spaces and commas in attribute types like "DOUBLE PRECISION" and "NUMERIC(10,2)".
+var mermaidTypeReplacer = strings.NewReplacer(" ", "_", ",", "_")
+
+func DumpMermaid(w io.Writer, tables []*Table, d Dialect, opts ...Option) error {
+	md := newOption(opts).dialect(d)
+	relations, err := fixRelations(tables)
 	if err != nil {
 		return err
 	}
@@ -24,19 +29,19 @@ func DumpMermaid(w io.Writer, tables []*Table, d Dialect) error {
 		fmt.Fprintf(w, "%s {\n", t.Name)
 		for _, c := range t.Columns {
 			if c.PrimaryKey {
-				fmt.Fprintf(w, "  %s %s PK\n", d.PrimaryKeyBaseType(c.Type), c.Name)
+				fmt.Fprintf(w, "  %s %s PK\n", mermaidTypeReplacer.Replace(md.baseType(c)), c.Name)
 			} else if c.LinkTable != "" {
 				if !c.AssociativeEntity {
 					if c.Nullable {
-						fmt.Fprintf(w, "  %s? %s FK\n", d.PrimaryKeyBaseType(c.Type), c.Name)
+						fmt.Fprintf(w, "  %s? %s FK\n", mermaidTypeReplacer.Replace(md.baseType(c)), c.Name)
 					} else {
-						fmt.Fprintf(w, "  %s %s FK\n", d.PrimaryKeyBaseType(c.Type), c.Name)
+						fmt.Fprintf(w, "  %s %s FK\n", mermaidTypeReplacer.Replace(md.baseType(c)), c.Name)
 					}
 				}
 			} else if c.Nullable {
-				fmt.Fprintf(w, "  %s? %s\n", d.TypeConversion(c.Type), c.Name)
+				fmt.Fprintf(w, "  %s? %s\n", mermaidTypeReplacer.Replace(md.columnType(c)), c.Name)
 			} else {
-				fmt.Fprintf(w, "  %s %s\n", d.TypeConversion(c.Type), c.Name)
+				fmt.Fprintf(w, "  %s %s\n", mermaidTypeReplacer.Replace(md.columnType(c)), c.Name)
 			}
 		}
 		io.WriteString(w, "}")
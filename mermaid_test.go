@@ -96,6 +96,33 @@ func TestDumpMermaid(t *testing.T) {
 			User }o--|| Job : job
 			`),
 		},
+		{
+			name: "identity strategy",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * job: *Job.id
+				* table: Job
+				  * !identity: uuid
+				  * @id
+				`),
+			},
+			want: TrimIndent(t, `
+			erDiagram
+
+			User {
+			  INTEGER id PK
+			  UUID job FK
+			}
+
+			Job {
+			  UUID id PK
+			}
+
+			User }o--|| Job : job
+			`),
+		},
 		{
 			name: "two table with associative entity",
 			args: args{
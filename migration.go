@@ -0,0 +1,197 @@
+package md2sql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MigrationFormat is the file format of versioned migrations.
+type MigrationFormat int
+
+const (
+	// GolangMigrate writes "000001_name.up.sql" and "000001_name.down.sql" for golang-migrate.
+	GolangMigrate MigrationFormat = iota
+	// Goose writes "00001_name.sql" that has "-- +goose Up" and "-- +goose Down" sections for goose.
+	Goose
+)
+
+// SnapshotFile is the name of the snapshot of the last model in the migration directory.
+const SnapshotFile = "md2sql_snapshot.json"
+
+var (
+	versionPattern   = regexp.MustCompile(`^(\d+)_`)
+	migrationNameRep = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Snapshot is the model of the last migration and the settings that decide its SQL types and names.
+type Snapshot struct {
+	Dialect             string            `json:"dialect"`
+	Types               map[string]string `json:"types,omitempty"`
+	Identity            string            `json:"identity,omitempty"`
+	Naming              NamingConvention  `json:"naming"`
+	BitReversedSequence bool              `json:"bitReversedSequence,omitempty"`
+	Tables              []*Table          `json:"tables"`
+}
+
+// DumpSnapshot writes the snapshot as JSON to compare it with the next version.
+func DumpSnapshot(w io.Writer, snapshot *Snapshot) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(snapshot)
+}
+
+// ReadSnapshot reads the snapshot written by DumpSnapshot.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// WriteMigration writes the next version of the migration files to the directory if the tables are changed
+// from the snapshot of the last run, and updates the snapshot. The first version creates all tables.
+// It returns the paths of the written migration files, which is empty if there are no changes.
+//
+// Migrations only have the changes of the tables, so it returns an error if the dialect, its type mapping,
+// the identity strategy, the naming convention or the bit-reversed sequences are changed from the snapshot.
+func WriteMigration(dir, name string, tables []*Table, d Dialect, format MigrationFormat, opts ...Option) ([]string, error) {
+	o := newOption(opts)
+	md := o.dialect(d)
+	current := &Snapshot{
+		Dialect:             md.Dialect.String(),
+		Types:               md.types,
+		Identity:            md.identity,
+		Naming:              o.naming,
+		BitReversedSequence: md.bitReversedSequence,
+		Tables:              tables,
+	}
+	var snapshot bytes.Buffer
+	if err := DumpSnapshot(&snapshot, current); err != nil {
+		return nil, err
+	}
+	var last []*Table
+	f, err := os.Open(filepath.Join(dir, SnapshotFile))
+	if err == nil {
+		s, err := ReadSnapshot(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		if s.Dialect != current.Dialect {
+			return nil, fmt.Errorf("the dialect is changed from %s of the snapshot to %s", s.Dialect, current.Dialect)
+		}
+		if !equalTypes(s.Types, current.Types) {
+			return nil, fmt.Errorf("the type mapping of %s is changed from the snapshot", current.Dialect)
+		}
+		if s.Identity != current.Identity {
+			return nil, fmt.Errorf("the identity strategy is changed from %q of the snapshot to %q", s.Identity, current.Identity)
+		}
+		if s.Naming != current.Naming {
+			return nil, errors.New("the naming convention is changed from the snapshot")
+		}
+		if s.BitReversedSequence != current.BitReversedSequence {
+			return nil, errors.New("the bit-reversed sequence option is changed from the snapshot")
+		}
+		last = s.Tables
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	up, err := Diff(last, tables)
+	if err != nil || up.IsEmpty() {
+		return nil, err
+	}
+	down, err := Diff(tables, last)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, WithoutBatchSeparator())
+	if format == Goose {
+		opts = append(opts, withGooseStatements())
+	}
+	var upSQL, downSQL bytes.Buffer
+	if err := up.DumpSQL(&upSQL, d, opts...); err != nil {
+		return nil, err
+	}
+	if err := down.DumpSQL(&downSQL, d, opts...); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	version, err := nextVersion(dir)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.Trim(migrationNameRep.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		name = "md2sql"
+	}
+
+	files := make(map[string]string)
+	var paths []string
+	switch format {
+	case GolangMigrate:
+		prefix := filepath.Join(dir, fmt.Sprintf("%06d_%s", version, name))
+		files[prefix+".up.sql"] = upSQL.String() + "\n"
+		files[prefix+".down.sql"] = downSQL.String() + "\n"
+		paths = []string{prefix + ".up.sql", prefix + ".down.sql"}
+	case Goose:
+		path := filepath.Join(dir, fmt.Sprintf("%05d_%s.sql", version, name))
+		files[path] = "-- +goose Up\n" + upSQL.String() + "\n\n-- +goose Down\n" + downSQL.String() + "\n"
+		paths = []string{path}
+	default:
+		return nil, fmt.Errorf("unknown migration format: %d", format)
+	}
+	for _, path := range paths {
+		if err := os.WriteFile(path, []byte(files[path]), 0o644); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, SnapshotFile), snapshot.Bytes(), 0o644); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// equalTypes reports whether the type mappings are the same. nil equals the empty mapping.
+func equalTypes(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
+
+// nextVersion returns the version after the largest version of the migration files in the directory.
+func nextVersion(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	last := 0
+	for _, e := range entries {
+		m := versionPattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		if v, err := strconv.Atoi(m[1]); err == nil && v > last {
+			last = v
+		}
+	}
+	return last + 1, nil
+}
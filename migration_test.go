@@ -0,0 +1,234 @@
+package md2sql
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	tables := parseForTest(t, TrimIndent(t, `
+	* table: User
+	  * !identity: always
+	  * @id
+	  * $name: string
+	  * job: *Job.id?
+	  * tags: *Tag.id[]
+	* table: Job
+	  * @id
+	* table: Tag
+	  * @id
+	`))
+	snapshot := &Snapshot{
+		Dialect:  "MySQL",
+		Types:    map[string]string{"string": "VARCHAR(255)"},
+		Identity: "uuid",
+		Naming:   StandardNamingConvention,
+		Tables:   tables,
+	}
+	var out bytes.Buffer
+	assert.NoError(t, DumpSnapshot(&out, snapshot))
+	got, err := ReadSnapshot(&out)
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot, got)
+}
+
+func TestWriteMigration(t *testing.T) {
+	v1 := TrimIndent(t, `
+	* table: User
+	  * @id
+	  * name: string
+	`)
+	v2 := TrimIndent(t, `
+	* table: User
+	  * @id
+	  * name: string
+	  * job: *Job.id?
+	* table: Job
+	  * @id
+	`)
+	dir := filepath.Join(t.TempDir(), "migrations")
+
+	paths, err := WriteMigration(dir, "Create Users", parseForTest(t, v1), PostgreSQL, GolangMigrate)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "000001_create_users.up.sql"),
+		filepath.Join(dir, "000001_create_users.down.sql"),
+	}, paths)
+	assert.FileExists(t, filepath.Join(dir, SnapshotFile))
+
+	paths, err = WriteMigration(dir, "Create Users", parseForTest(t, v1), PostgreSQL, GolangMigrate)
+	assert.NoError(t, err)
+	assert.Empty(t, paths)
+
+	paths, err = WriteMigration(dir, "add job", parseForTest(t, v2), PostgreSQL, GolangMigrate)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "000002_add_job.up.sql"),
+		filepath.Join(dir, "000002_add_job.down.sql"),
+	}, paths)
+	up, err := os.ReadFile(paths[0])
+	assert.NoError(t, err)
+	assert.Equal(t, TrimIndent(t, `
+	CREATE TABLE Job(
+		id SERIAL,
+		PRIMARY KEY(id)
+	);
+
+	ALTER TABLE User ADD COLUMN job INTEGER;
+
+	ALTER TABLE User ADD FOREIGN KEY(job) REFERENCES Job(id);
+	`)+"\n", string(up))
+	down, err := os.ReadFile(paths[1])
+	assert.NoError(t, err)
+	assert.Equal(t, TrimIndent(t, `
+	ALTER TABLE User DROP CONSTRAINT User_job_fkey;
+
+	DROP TABLE Job;
+
+	ALTER TABLE User DROP COLUMN job;
+	`)+"\n", string(down))
+}
+
+func TestWriteMigration_Goose(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := WriteMigration(dir, "init", parseForTest(t, TrimIndent(t, `
+	* table: Job
+	  * @id
+	`)), SQLServer, Goose)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "00001_init.sql")}, paths)
+	got, err := os.ReadFile(paths[0])
+	assert.NoError(t, err)
+	assert.Equal(t, TrimIndent(t, `
+	-- +goose Up
+	-- +goose StatementBegin
+	CREATE TABLE [Job](
+		[id] INTEGER IDENTITY(1,1),
+		PRIMARY KEY([id])
+	);
+	-- +goose StatementEnd
+
+	-- +goose Down
+	-- +goose StatementBegin
+	DROP TABLE [Job];
+	-- +goose StatementEnd
+	`)+"\n", string(got))
+}
+
+func TestWriteMigration_GooseBlock(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := WriteMigration(dir, "init", parseForTest(t, TrimIndent(t, `
+	* table: Job
+	  * @id
+	`)), Oracle11g, Goose)
+	assert.NoError(t, err)
+	got, err := os.ReadFile(paths[0])
+	assert.NoError(t, err)
+	assert.Equal(t, TrimIndent(t, `
+	-- +goose Up
+	-- +goose StatementBegin
+	CREATE SEQUENCE JOB_ID_SEQ;
+	-- +goose StatementEnd
+
+	-- +goose StatementBegin
+	CREATE TABLE JOB(
+		ID NUMBER(19),
+		PRIMARY KEY(ID)
+	);
+	-- +goose StatementEnd
+
+	-- +goose StatementBegin
+	CREATE OR REPLACE TRIGGER JOB_ID_TRG
+	BEFORE INSERT ON JOB
+	FOR EACH ROW
+	WHEN (new.ID IS NULL)
+	BEGIN
+		:new.ID := JOB_ID_SEQ.NEXTVAL;
+	END;
+	-- +goose StatementEnd
+
+	-- +goose Down
+	-- +goose StatementBegin
+	DROP TABLE JOB;
+	-- +goose StatementEnd
+
+	-- +goose StatementBegin
+	DROP SEQUENCE JOB_ID_SEQ;
+	-- +goose StatementEnd
+	`)+"\n", string(got))
+}
+
+func TestWriteMigration_SettingsChanged(t *testing.T) {
+	tables := parseForTest(t, TrimIndent(t, `
+	* table: Job
+	  * @id
+	  * name: string
+	`))
+	dir := t.TempDir()
+	_, err := WriteMigration(dir, "init", tables, MySQL, GolangMigrate)
+	assert.NoError(t, err)
+
+	_, err = WriteMigration(dir, "v2", tables, PostgreSQL, GolangMigrate)
+	assert.Error(t, err)
+	_, err = WriteMigration(dir, "v2", tables, MySQL, GolangMigrate, WithTypeMapping(TypeMapping{MySQL: {"string": "VARCHAR(255)"}}))
+	assert.Error(t, err)
+	_, err = WriteMigration(dir, "v2", tables, MySQL, GolangMigrate, WithIdentity("uuid"))
+	assert.Error(t, err)
+	_, err = WriteMigration(dir, "v2", tables, MySQL, GolangMigrate, WithNamingConvention(StandardNamingConvention))
+	assert.Error(t, err)
+	paths, err := WriteMigration(dir, "v2", tables, MySQL, GolangMigrate, WithTypeMapping(TypeMapping{PostgreSQL: {"string": "citext"}}))
+	assert.NoError(t, err)
+	assert.Empty(t, paths)
+
+	dir = t.TempDir()
+	_, err = WriteMigration(dir, "init", tables, Spanner, GolangMigrate)
+	assert.NoError(t, err)
+	_, err = WriteMigration(dir, "v2", tables, Spanner, GolangMigrate, WithBitReversedSequence())
+	assert.Error(t, err)
+}
+
+func TestSQLite_ExecuteWriteMigration(t *testing.T) {
+	versions := []string{
+		TrimIndent(t, `
+		* table: User
+		  * @id
+		  * name: string
+		  * tags: *Tag.id[]
+		* table: Tag
+		  * @id
+		`),
+		TrimIndent(t, `
+		* table: User
+		  * @id
+		  * name: string
+		  * nickname: string?
+		  * job: *Job.id?
+		* table: Job
+		  * @id
+		* table: Tag
+		  * @id
+		`),
+	}
+	dir := t.TempDir()
+	var ups, downs []string
+	for i, src := range versions {
+		paths, err := WriteMigration(dir, "v", parseForTest(t, src), SQLite, GolangMigrate)
+		assert.NoError(t, err)
+		assert.Len(t, paths, 2, i)
+		ups = append(ups, paths[0])
+		downs = append([]string{paths[1]}, downs...)
+	}
+	db := openSQLite(t)
+	for _, path := range append(ups, downs...) {
+		script, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		execSQLite(t, db, string(script))
+	}
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'").Scan(&count))
+	assert.Equal(t, 0, count)
+}
@@ -0,0 +1,101 @@
+package md2sql
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode/utf8"
+)
+
+// NamingConvention has templates of constraint names.
+//
+// Templates can have the following placeholders:
+//
+//   - {table}: table name
+//   - {columns}: column names joined by "_"
+//   - {ref}: referred table name (foreign key only)
+//   - {ref_columns}: referred column names joined by "_" (foreign key only)
+//
+// Empty template means the constraint is emitted without name.
+type NamingConvention struct {
+	PrimaryKey string `json:"primaryKey,omitempty"`
+	ForeignKey string `json:"foreignKey,omitempty"`
+	Unique     string `json:"unique,omitempty"`
+}
+
+// DefaultNamingConvention is compatible with older versions. Only unique indexes have names.
+var DefaultNamingConvention = NamingConvention{
+	Unique: "INDEX_{table}_{columns}",
+}
+
+// StandardNamingConvention names all constraints.
+var StandardNamingConvention = NamingConvention{
+	PrimaryKey: "pk_{table}",
+	ForeignKey: "fk_{table}_{columns}_{ref}",
+	Unique:     "uq_{table}_{columns}",
+}
+
+func (n NamingConvention) primaryKey(d Dialect, table string, columns []string) string {
+	return expandName(d, n.PrimaryKey, table, columns, "", nil)
+}
+
+func (n NamingConvention) foreignKey(d Dialect, fk *foreignKey) string {
+	return expandName(d, n.ForeignKey, fk.Table, fk.Columns, fk.RefTable, fk.RefColumns)
+}
+
+func (n NamingConvention) unique(d Dialect, table string, columns []string) string {
+	return expandName(d, n.Unique, table, columns, "", nil)
+}
+
+func sequenceName(d Dialect, table, column string) string {
+	return expandName(d, "{table}_{columns}_seq", table, []string{column}, "", nil)
+}
+
+func triggerName(d Dialect, table, column string) string {
+	return expandName(d, "{table}_{columns}_trg", table, []string{column}, "", nil)
+}
+
+func expandName(d Dialect, template, table string, columns []string, ref string, refColumns []string) string {
+	if template == "" {
+		return ""
+	}
+	name := strings.NewReplacer(
+		"{table}", table,
+		"{columns}", strings.Join(columns, "_"),
+		"{ref}", ref,
+		"{ref_columns}", strings.Join(refColumns, "_"),
+	).Replace(template)
+	limit, inBytes := d.IdentifierLimit()
+	return shortenIdentifier(name, limit, inBytes)
+}
+
+// shortenIdentifier truncates the name and appends hash of the original name
+// to keep names unique and stable if it exceeds the limit.
+func shortenIdentifier(name string, limit int, inBytes bool) string {
+	length := utf8.RuneCountInString(name)
+	if inBytes {
+		length = len(name)
+	}
+	if limit <= 0 || length <= limit {
+		return name
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	suffix := fmt.Sprintf("_%08x", h.Sum32())
+
+	var b strings.Builder
+	size := 0
+	for _, r := range name {
+		l := 1
+		if inBytes {
+			l = utf8.RuneLen(r)
+		}
+		if size+l > limit-len(suffix) {
+			break
+		}
+		b.WriteRune(r)
+		size += l
+	}
+	b.WriteString(suffix)
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package md2sql
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortenIdentifier(t *testing.T) {
+	type args struct {
+		name    string
+		limit   int
+		inBytes bool
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "short name",
+			args: args{
+				name:    "pk_User",
+				limit:   63,
+				inBytes: true,
+			},
+			want: "pk_User",
+		},
+		{
+			name: "no limit",
+			args: args{
+				name: strings.Repeat("a", 100),
+			},
+			want: strings.Repeat("a", 100),
+		},
+		{
+			name: "truncate",
+			args: args{
+				name:    "fk_" + strings.Repeat("a", 70),
+				limit:   20,
+				inBytes: true,
+			},
+			want: "fk_aaaaaaaa_d4d38421",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, shortenIdentifier(tt.args.name, tt.args.limit, tt.args.inBytes))
+		})
+	}
+}
+
+func TestShortenIdentifier_Multibyte(t *testing.T) {
+	name := "fk_" + strings.Repeat("ユーザー", 20)
+
+	pg := shortenIdentifier(name, 63, true)
+	assert.LessOrEqual(t, len(pg), 63)
+	assert.True(t, utf8.ValidString(pg))
+
+	my := shortenIdentifier(name, 64, false)
+	assert.Equal(t, 64, utf8.RuneCountInString(my))
+	assert.Greater(t, len(my), 64)
+
+	// stable and unique
+	assert.Equal(t, pg, shortenIdentifier(name, 63, true))
+	assert.NotEqual(t, pg, shortenIdentifier(name+"2", 63, true))
+}
@@ -4,114 +4,163 @@ import (
 	"strings"
 )
 
-//go:generate go run github.com/dmarkham/enumer -type=Dialect
-
-type Dialect int
+type Filter int
 
 const (
-	PostgreSQL Dialect = iota
-	MySQL
-	SQLite
+	OutputFields Filter = 1 << iota
+	OutputKeys
+	OutputForeignKeyDef
+	OutputMaster
+	OutputTransaction
+	OutputWork
+	OutputSummary
+	OutputView
+	OutputAE
+
+	OutputAllTable = OutputMaster | OutputTransaction | OutputWork | OutputSummary | OutputView | OutputAE
+
+	OutputSkeltonModel  = OutputMaster | OutputTransaction | OutputAE
+	OutputBirdViewModel = OutputMaster | OutputTransaction | OutputAE | OutputKeys
+
+	OutputAll              = OutputExceptForeignKey | OutputForeignKeyDef
+	OutputExceptForeignKey = OutputFields | OutputKeys | OutputAllTable
 )
 
-func ToDialect(src string) Dialect {
-	switch strings.ToLower(src) {
-	case "postgres":
-		return PostgreSQL
-	case "postgresql":
-		return PostgreSQL
-	case "pg":
-		return PostgreSQL
-	case "mysql":
-		return MySQL
-	case "maria":
-		return MySQL
-	case "mariadb":
-		return MySQL
-	case "sqlite":
-		return SQLite
-	}
-	return PostgreSQL
+type option struct {
+	sourceOrder         bool
+	naming              NamingConvention
+	types               TypeMapping
+	bitReversedSequence bool
+	dataset             string
+	identity            string
+	noBatchSeparator    bool
+	ifNotExists         bool
+	dropTables          bool
+	schema              string
+	// gooseStatements wraps each statement by the annotations of goose (WriteMigration)
+	gooseStatements bool
+}
+
+// Option modifies the behavior of the Dump functions.
+type Option func(o *option)
+
+// WithSourceOrder keeps the table order of the source in the generated SQL.
+// Foreign keys that refer tables defined later are added by ALTER TABLE statements at the end.
+func WithSourceOrder() Option {
+	return func(o *option) {
+		o.sourceOrder = true
+	}
+}
+
+// WithNamingConvention sets templates of constraint names.
+func WithNamingConvention(n NamingConvention) Option {
+	return func(o *option) {
+		o.naming = n
+	}
 }
 
-func (d Dialect) PrimaryKeySQLType(t string, autoIncrement bool) string {
-	if t == "" {
-		if autoIncrement {
-			switch d {
-			case PostgreSQL:
-				return "SERIAL"
-			case MySQL:
-				return "SERIAL"
-			case SQLite:
-				return "INTEGER AUTOINCREMENT"
+// WithTypeMapping overrides the built-in type mapping. It can be used multiple times and later one wins.
+func WithTypeMapping(m TypeMapping) Option {
+	return func(o *option) {
+		for d, types := range m {
+			if o.types[d] == nil {
+				o.types[d] = make(map[string]string)
+			}
+			for k, v := range types {
+				o.types[d][strings.ToLower(k)] = v
 			}
-		} else {
-			return d.PrimaryKeyBaseType("")
 		}
 	}
-	return d.TypeConversion(t)
 }
 
-func (d Dialect) EnableForeignKey(hasForeignKey bool) string {
-	if d == SQLite && hasForeignKey {
-		return "PRAGMA foreign_keys = ON;\n\n"
+// WithBitReversedSequence uses bit-reversed sequences for auto-increment keys on Spanner instead of UUID.
+func WithBitReversedSequence() Option {
+	return func(o *option) {
+		o.bitReversedSequence = true
 	}
-	return ""
 }
 
-func (d Dialect) PrimaryKeyBaseType(t string) string {
-	if t == "" {
-		switch d {
-		case PostgreSQL:
-			return "INTEGER"
-		case MySQL:
-			return "INTEGER"
-		case SQLite:
-			return "INTEGER"
-		}
+// WithDataset qualifies table names with the dataset on BigQuery.
+func WithDataset(dataset string) Option {
+	return func(o *option) {
+		o.dataset = dataset
 	}
-	return d.TypeConversion(t)
 }
 
-func (d Dialect) TypeConversion(t string) string {
-	switch strings.ToLower(t) {
-	case "string":
-		fallthrough
-	case "text":
-		return "TEXT"
-	case "blob":
-		fallthrough
-	case "lob":
-		switch d {
-		case PostgreSQL:
-			return "BYTEA"
-		case MySQL:
-			return "BLOB"
-		case SQLite:
-			return "BLOB"
-		}
+// WithIdentity sets the default identity strategy of auto-increment keys like "identity" or "uuid".
+// Tables can override it by "!identity" annotation. Strategies that the dialect doesn't have are ignored.
+func WithIdentity(strategy string) Option {
+	return func(o *option) {
+		o.identity = strategy
 	}
-	return strings.ToUpper(t)
 }
 
-type Filter int
+// WithoutBatchSeparator omits the batch separators like "GO" of SQL Server.
+// Migration tools that run the script by themselves don't accept them.
+func WithoutBatchSeparator() Option {
+	return func(o *option) {
+		o.noBatchSeparator = true
+	}
+}
 
-const (
-	OutputFields Filter = 1 << iota
-	OutputKeys
-	OutputForeignKeyDef
-	OutputMaster
-	OutputTransaction
-	OutputWork
-	OutputSummary
-	OutputView
-	OutputAE
+// withGooseStatements wraps each statement by "-- +goose StatementBegin" and "-- +goose StatementEnd"
+// because goose splits the other statements by semicolons, which breaks PL/SQL blocks.
+func withGooseStatements() Option {
+	return func(o *option) {
+		o.gooseStatements = true
+	}
+}
 
-	OutputAllTable = OutputMaster | OutputTransaction | OutputWork | OutputSummary | OutputView | OutputAE
+// WithIfNotExists writes CREATE TABLE IF NOT EXISTS and CREATE INDEX IF NOT EXISTS to run the script repeatedly.
+// Foreign keys in cycles are added only if they don't exist. Statements are written as is with a warning comment
+// on dialects that don't have them.
+func WithIfNotExists() Option {
+	return func(o *option) {
+		o.ifNotExists = true
+	}
+}
 
-	OutputSkeltonModel  = OutputMaster | OutputTransaction | OutputAE
-	OutputBirdViewModel = OutputMaster | OutputTransaction | OutputAE | OutputKeys
+// WithDropTables writes DROP TABLE IF EXISTS of all tables including associative entities before creating them.
+// Tables are dropped in reverse dependency order with CASCADE if the dialect has it. Otherwise foreign key checks are
+// disabled, or foreign keys in cycles are dropped first only if they exist. DumpSQL returns an error if the dialect can't do either.
+func WithDropTables() Option {
+	return func(o *option) {
+		o.dropTables = true
+	}
+}
 
-	OutputAll              = OutputExceptForeignKey | OutputForeignKeyDef
-	OutputExceptForeignKey = OutputFields | OutputKeys | OutputAllTable
-)
+// WithSchema sets the schema name of the schema definitions like Atlas HCL.
+// The default is "main" on SQLite, "dbo" on SQL Server and "public" on the others.
+func WithSchema(name string) Option {
+	return func(o *option) {
+		o.schema = name
+	}
+}
+
+func newOption(opts []Option) *option {
+	o := &option{
+		naming: DefaultNamingConvention,
+		types:  make(TypeMapping),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// dialect returns the dialect with the options. nil means PostgreSQL.
+func (o *option) dialect(d Dialect) mappedDialect {
+	if d == nil {
+		d = PostgreSQL
+	}
+	result := mappedDialect{
+		Dialect:             d,
+		types:               o.types[d],
+		bitReversedSequence: o.bitReversedSequence && d.Capabilities().BitReversedSequence,
+		identity:            o.identity,
+	}
+	if d.Capabilities().Dataset {
+		result.dataset = o.dataset
+	}
+	return result
+}
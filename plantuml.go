@@ -84,8 +84,9 @@ var theme = map[TableType]map[bool]string{
 	},
 }
 
-func DumpPlantUML(w io.Writer, tables []*Table, d Dialect) error {
-	relations, err := fixRelations(tables, d)
+func DumpPlantUML(w io.Writer, tables []*Table, d Dialect, opts ...Option) error {
+	md := newOption(opts).dialect(d)
+	relations, err := fixRelations(tables)
 	if err != nil {
 		return err
 	}
@@ -99,9 +100,9 @@ func DumpPlantUML(w io.Writer, tables []*Table, d Dialect) error {
 		for _, c := range t.Columns {
 			if c.PrimaryKey {
 				if c.AutoIncrement {
-					fmt.Fprintf(w, "  *%s:%s <<PK>>\n", c.Name, d.PrimaryKeyBaseType(c.Type))
+					fmt.Fprintf(w, "  *%s:%s <<PK>>\n", c.Name, md.baseType(c))
 				} else {
-					fmt.Fprintf(w, "  *%s:%s\n", c.Name, d.PrimaryKeyBaseType(c.Type))
+					fmt.Fprintf(w, "  *%s:%s\n", c.Name, md.baseType(c))
 				}
 			}
 		}
@@ -113,15 +114,15 @@ func DumpPlantUML(w io.Writer, tables []*Table, d Dialect) error {
 			if c.LinkTable != "" {
 				if !c.AssociativeEntity {
 					if c.Nullable {
-						fmt.Fprintf(w, "  %s:%s <<FK>>\n", c.Name, d.PrimaryKeyBaseType(c.Type))
+						fmt.Fprintf(w, "  %s:%s <<FK>>\n", c.Name, md.baseType(c))
 					} else {
-						fmt.Fprintf(w, "  *%s:%s <<FK>>\n", c.Name, d.PrimaryKeyBaseType(c.Type))
+						fmt.Fprintf(w, "  *%s:%s <<FK>>\n", c.Name, md.baseType(c))
 					}
 				}
 			} else if c.Nullable {
-				fmt.Fprintf(w, "  %s:%s\n", c.Name, d.TypeConversion(c.Type))
+				fmt.Fprintf(w, "  %s:%s\n", c.Name, md.columnType(c))
 			} else {
-				fmt.Fprintf(w, "  *%s:%s\n", c.Name, d.TypeConversion(c.Type))
+				fmt.Fprintf(w, "  *%s:%s\n", c.Name, md.columnType(c))
 			}
 		}
 		fmt.Fprintf(w, "}\n\n")
@@ -0,0 +1,370 @@
+package md2sql
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+var prismaProviders = map[Dialect]string{
+	PostgreSQL: "postgresql",
+	MySQL:      "mysql",
+	SQLite:     "sqlite",
+	SQLServer:  "sqlserver",
+}
+
+// prismaScalars are the Prisma types of the portable types.
+var prismaScalars = map[string]string{
+	"string":      "String",
+	"text":        "String",
+	"varchar":     "String",
+	"char":        "String",
+	"uuid":        "String",
+	"smallint":    "Int",
+	"integer":     "Int",
+	"bigint":      "BigInt",
+	"float":       "Float",
+	"double":      "Float",
+	"decimal":     "Decimal",
+	"bool":        "Boolean",
+	"date":        "DateTime",
+	"time":        "DateTime",
+	"timetz":      "DateTime",
+	"timestamp":   "DateTime",
+	"timestamptz": "DateTime",
+	"json":        "Json",
+	"binary":      "Bytes",
+}
+
+// prismaNativeTypes are the native type attributes of the portable types that differ from the default of the scalar.
+var prismaNativeTypes = map[string]map[string]string{
+	"postgresql": {
+		"varchar":     "@db.VarChar({args})",
+		"char":        "@db.Char({args})",
+		"uuid":        "@db.Uuid",
+		"smallint":    "@db.SmallInt",
+		"float":       "@db.Real",
+		"decimal":     "@db.Decimal({args})",
+		"date":        "@db.Date",
+		"time":        "@db.Time({args})",
+		"timetz":      "@db.Timetz({args})",
+		"timestamptz": "@db.Timestamptz({args})",
+	},
+	"mysql": {
+		"string":            "@db.Text",
+		"text":              "@db.Text",
+		"varchar":           "@db.VarChar({args})",
+		"char":              "@db.Char({args})",
+		"uuid":              "@db.Char(36)",
+		"smallint":          "@db.SmallInt",
+		"smallint unsigned": "@db.UnsignedSmallInt",
+		"integer unsigned":  "@db.UnsignedInt",
+		"bigint unsigned":   "@db.UnsignedBigInt",
+		"float":             "@db.Float",
+		"decimal":           "@db.Decimal({args})",
+		"date":              "@db.Date",
+		"time":              "@db.Time({args})",
+		"timetz":            "@db.Time({args})",
+	},
+	"sqlserver": {
+		"string":   "@db.NVarChar(Max)",
+		"text":     "@db.NVarChar(Max)",
+		"varchar":  "@db.NVarChar({args})",
+		"char":     "@db.NChar({args})",
+		"uuid":     "@db.UniqueIdentifier",
+		"smallint": "@db.SmallInt",
+		"float":    "@db.Real",
+		"decimal":  "@db.Decimal({args})",
+		"date":     "@db.Date",
+		"time":     "@db.Time",
+		"timetz":   "@db.Time",
+	},
+}
+
+// prismaDefaults are the default functions of the identity strategies. The others are autoincrement().
+var prismaDefaults = map[string]string{
+	"uuid":   "uuid()",
+	"uuidv7": "uuid(7)",
+	"ulid":   "ulid()",
+}
+
+// prismaRelation is the relation between two models. Columns is empty for implicit many-to-many relations.
+type prismaRelation struct {
+	from, to   string
+	label      string
+	columns    []string
+	refColumns []string
+	nullable   bool
+	oneToOne   bool
+	name       string
+}
+
+type prismaField struct {
+	name  string
+	typ   string
+	attrs []string
+}
+
+// DumpPrisma writes the tables as models of the Prisma schema. The provider of the datasource is decided by the dialect.
+// Foreign keys have relation fields on both sides named after the other models like "prisma db pull",
+// and relations that can't be told apart by the model names like self references have relation names.
+// Associative entities ("[]") become implicit many-to-many relations, whose tables are managed by Prisma.
+// Tables with compound ids can't have them, so their associative entities become explicit join models.
+func DumpPrisma(w io.Writer, tables []*Table, d Dialect, opts ...Option) error {
+	o := newOption(opts)
+	md := o.dialect(d)
+	provider, ok := prismaProviders[md.Dialect]
+	if !ok {
+		return fmt.Errorf("Prisma doesn't support %s", md.Dialect)
+	}
+	if _, err := fixRelations(tables); err != nil {
+		return err
+	}
+	tables = prismaJoinModels(tables)
+	p := &ddlParser{d: md}
+
+	var relations []*prismaRelation
+	for _, t := range tables {
+		pks := primaryKeys([]*Table{t})[t.Name]
+		for _, fk := range foreignKeys(t) {
+			r := &prismaRelation{from: t.Name, to: fk.RefTable, label: fk.Columns[0], columns: fk.Columns, refColumns: fk.RefColumns}
+			for _, c := range t.Columns {
+				if contains(fk.Columns, c.Name) && c.Nullable {
+					r.nullable = true
+				}
+				if len(fk.Columns) == 1 && c.Name == fk.Columns[0] && c.Index {
+					r.oneToOne = true
+				}
+			}
+			r.oneToOne = r.oneToOne || equalStrings(fk.Columns, pks)
+			relations = append(relations, r)
+		}
+		for _, c := range t.Columns {
+			if c.LinkTable != "" && c.AssociativeEntity {
+				relations = append(relations, &prismaRelation{from: t.Name, to: c.LinkTable, label: c.Name})
+			}
+		}
+	}
+	pairs := make(map[string]int)
+	pair := func(r *prismaRelation) string {
+		if r.from < r.to {
+			return r.from + "/" + r.to
+		}
+		return r.to + "/" + r.from
+	}
+	for _, r := range relations {
+		pairs[pair(r)]++
+	}
+	for _, r := range relations {
+		if r.from == r.to || pairs[pair(r)] > 1 {
+			r.name = r.from + "_" + r.label + "To" + r.to
+		}
+	}
+
+	fmt.Fprintf(w, "datasource db {\n  provider = %q\n  url      = env(\"DATABASE_URL\")\n}\n", provider)
+	for _, t := range tables {
+		var fields []prismaField
+		var pks []string
+		for _, c := range t.Columns {
+			if c.AssociativeEntity {
+				continue
+			}
+			sqlType := md.columnType(c)
+			nullable := c.Nullable && !c.PrimaryKey
+			f := prismaField{name: c.Name}
+			var native string
+			f.typ, native = prismaType(p, provider, sqlType)
+			if nullable {
+				f.typ += "?"
+			}
+			if c.PrimaryKey {
+				pks = append(pks, c.Name)
+			}
+			if c.Index {
+				f.attrs = append(f.attrs, "@unique")
+			}
+			if c.PrimaryKey && c.AutoIncrement && c.LinkTable == "" {
+				f.attrs = append(f.attrs, "@default("+prismaDefault(md, c)+")")
+			}
+			if native != "" {
+				f.attrs = append(f.attrs, native)
+			}
+			fields = append(fields, f)
+		}
+		if len(pks) == 1 {
+			for i := range fields {
+				if fields[i].name == pks[0] {
+					fields[i].attrs = append([]string{"@id"}, fields[i].attrs...)
+				}
+			}
+		}
+		for _, r := range relations {
+			if r.from == t.Name && r.columns != nil {
+				f := prismaField{name: r.relationField(), typ: r.to, attrs: []string{r.attribute(true)}}
+				if r.nullable {
+					f.typ += "?"
+				}
+				fields = append(fields, f)
+			} else if r.from == t.Name {
+				f := prismaField{name: r.label, typ: r.to + "[]"}
+				if r.name != "" {
+					f.attrs = []string{r.attribute(false)}
+				}
+				fields = append(fields, f)
+			}
+		}
+		for _, r := range relations {
+			if r.to != t.Name {
+				continue
+			}
+			f := prismaField{name: r.backField(), typ: r.from + "[]"}
+			if r.columns != nil && r.oneToOne {
+				f.typ = r.from + "?"
+			}
+			if r.name != "" {
+				f.attrs = []string{r.attribute(false)}
+			}
+			fields = append(fields, f)
+		}
+
+		io.WriteString(w, "\n")
+		if t.Description != "" {
+			for _, line := range strings.Split(t.Description, "\n") {
+				fmt.Fprintf(w, "/// %s\n", strings.TrimSpace(line))
+			}
+		}
+		fmt.Fprintf(w, "model %s {\n", t.Name)
+		writePrismaFields(w, fields)
+		if len(pks) > 1 {
+			if len(fields) > 0 {
+				io.WriteString(w, "\n")
+			}
+			fmt.Fprintf(w, "  @@id([%s])\n", strings.Join(pks, ", "))
+		}
+		io.WriteString(w, "}\n")
+	}
+	return nil
+}
+
+// prismaJoinModels returns the tables with the explicit join models of the associative entities that can't be
+// implicit many-to-many relations, because Prisma needs a single @id on both sides of them.
+// The join models are the same as the tables that DumpSQL creates except for the column of the other side of
+// self references, which is named after the associative column.
+func prismaJoinModels(tables []*Table) []*Table {
+	pks := primaryKeys(tables)
+	explicit := func(t *Table, c *Column) bool {
+		return c.AssociativeEntity && (len(pks[t.Name]) != 1 || !equalStrings(pks[c.LinkTable], []string{c.LinkColumn}))
+	}
+	var result, joins []*Table
+	for _, t := range tables {
+		model := &Table{}
+		*model = *t
+		model.Columns = nil
+		for _, c := range t.Columns {
+			if !explicit(t, c) {
+				model.Columns = append(model.Columns, c)
+				continue
+			}
+			join := &Table{Name: t.Name + "_" + c.Name, Type: AssociativeEntity, Columns: []*Column{{Name: "id", PrimaryKey: true, AutoIncrement: true}}}
+			for _, pk := range t.Columns {
+				if pk.PrimaryKey {
+					join.Columns = append(join.Columns, &Column{Name: t.Name + "_" + pk.Name, Type: pk.Type, Identity: pk.Identity, LinkTable: t.Name, LinkColumn: pk.Name, Nullable: true})
+				}
+			}
+			other := c.LinkTable + "_" + c.LinkColumn
+			if c.LinkTable == t.Name {
+				other = c.Name + "_" + c.LinkColumn
+			}
+			join.Columns = append(join.Columns, &Column{Name: other, Type: c.Type, Identity: c.Identity, LinkTable: c.LinkTable, LinkColumn: c.LinkColumn, Nullable: true})
+			joins = append(joins, join)
+		}
+		result = append(result, model)
+	}
+	return append(result, joins...)
+}
+
+// relationField returns the name of the relation field on the side of the foreign key.
+func (r *prismaRelation) relationField() string {
+	if r.name != "" {
+		return r.to + "_" + r.name
+	}
+	return r.to
+}
+
+// backField returns the name of the relation field on the referred side. Self references have "other_" like "prisma db pull".
+func (r *prismaRelation) backField() string {
+	if r.from == r.to {
+		return "other_" + r.relationField()
+	}
+	if r.name != "" {
+		return r.from + "_" + r.name
+	}
+	return r.from
+}
+
+// attribute returns @relation attribute. The side of the foreign key has fields and references.
+func (r *prismaRelation) attribute(withFields bool) string {
+	var args []string
+	if r.name != "" {
+		args = append(args, fmt.Sprintf("%q", r.name))
+	}
+	if withFields {
+		args = append(args, fmt.Sprintf("fields: [%s]", strings.Join(r.columns, ", ")), fmt.Sprintf("references: [%s]", strings.Join(r.refColumns, ", ")))
+	}
+	return "@relation(" + strings.Join(args, ", ") + ")"
+}
+
+// prismaType returns the Prisma type and the native type attribute of the SQL type of the dialect.
+// Types that Prisma doesn't have become Unsupported("type").
+func prismaType(p *ddlParser, provider, sqlType string) (string, string) {
+	var nullable bool
+	portable := p.portableType(sqlType, &nullable)
+	name, args := splitType(portable)
+	scalar, ok := prismaScalars[strings.TrimSuffix(name, " unsigned")]
+	if !ok {
+		return fmt.Sprintf("Unsupported(%q)", sqlType), ""
+	}
+	if native, ok := prismaNativeTypes[provider][name]; ok {
+		return scalar, expandType(native, strings.ReplaceAll(args, ",", ", "))
+	}
+	return scalar, ""
+}
+
+// prismaDefault returns the default function of the auto-increment key by its identity strategy.
+func prismaDefault(md mappedDialect, c *Column) string {
+	if _, ok := md.identityOf(c); !ok {
+		return "autoincrement()"
+	}
+	identity := c.Identity
+	if identity == "" {
+		identity = md.identity
+	}
+	strategy, function, ok := strings.Cut(identity, ":")
+	if ok {
+		return fmt.Sprintf("dbgenerated(%q)", strings.TrimSpace(function))
+	}
+	if f, ok := prismaDefaults[strings.TrimSpace(strategy)]; ok {
+		return f
+	}
+	return "autoincrement()"
+}
+
+// writePrismaFields writes the fields with the names and the types aligned like "prisma format".
+func writePrismaFields(w io.Writer, fields []prismaField) {
+	nameWidth, typeWidth := 0, 0
+	for _, f := range fields {
+		if len(f.name) > nameWidth {
+			nameWidth = len(f.name)
+		}
+		if len(f.typ) > typeWidth {
+			typeWidth = len(f.typ)
+		}
+	}
+	for _, f := range fields {
+		if len(f.attrs) == 0 {
+			fmt.Fprintf(w, "  %-*s %s\n", nameWidth, f.name, f.typ)
+		} else {
+			fmt.Fprintf(w, "  %-*s %-*s %s\n", nameWidth, f.name, typeWidth, f.typ, strings.Join(f.attrs, " "))
+		}
+	}
+}
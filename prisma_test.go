@@ -0,0 +1,226 @@
+package md2sql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpPrisma(t *testing.T) {
+	type args struct {
+		src     string
+		dialect Dialect
+		opts    []Option
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "PostgreSQL",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  Users who signed up.
+				  * @id
+				  * $email: varchar(100)
+				  * rate: double?
+				  * price: decimal(10, 2)
+				  * job: *Job.id?
+				  * boss: *User.id?
+				* table: Job
+				  * @id
+				* table: Profile
+				  * @user: *User.id
+				  * @kind: smallint
+				`),
+				dialect: PostgreSQL,
+			},
+			want: TrimIndent(t, `
+			datasource db {
+			  provider = "postgresql"
+			  url      = env("DATABASE_URL")
+			}
+
+			/// Users who signed up.
+			model User {
+			  id                         Int       @id @default(autoincrement())
+			  email                      String    @unique @db.VarChar(100)
+			  rate                       Float?
+			  price                      Decimal   @db.Decimal(10, 2)
+			  job                        Int?
+			  boss                       Int?
+			  Job                        Job?      @relation(fields: [job], references: [id])
+			  User_User_bossToUser       User?     @relation("User_bossToUser", fields: [boss], references: [id])
+			  other_User_User_bossToUser User[]    @relation("User_bossToUser")
+			  Profile                    Profile[]
+			}
+
+			model Job {
+			  id   Int    @id @default(autoincrement())
+			  User User[]
+			}
+
+			model Profile {
+			  user Int
+			  kind Int  @db.SmallInt
+			  User User @relation(fields: [user], references: [id])
+
+			  @@id([user, kind])
+			}
+			`),
+		},
+		{
+			name: "MySQL with associative entity",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * $profile: *Profile.id
+				  * tags: *Tag.id[]
+				* table: Profile
+				  * @id
+				* table: Tag
+				  * @id
+				  * name: string
+				`),
+				dialect: MySQL,
+			},
+			want: TrimIndent(t, `
+			datasource db {
+			  provider = "mysql"
+			  url      = env("DATABASE_URL")
+			}
+
+			model User {
+			  id      BigInt  @id @default(autoincrement()) @db.UnsignedBigInt
+			  profile BigInt  @unique @db.UnsignedBigInt
+			  Profile Profile @relation(fields: [profile], references: [id])
+			  tags    Tag[]
+			}
+
+			model Profile {
+			  id   BigInt @id @default(autoincrement()) @db.UnsignedBigInt
+			  User User?
+			}
+
+			model Tag {
+			  id   BigInt @id @default(autoincrement()) @db.UnsignedBigInt
+			  name String @db.Text
+			  User User[]
+			}
+			`),
+		},
+		{
+			name: "uuid identity",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * at: timestamptz?
+				`),
+				dialect: PostgreSQL,
+				opts:    []Option{WithIdentity("uuidv7")},
+			},
+			want: TrimIndent(t, `
+			datasource db {
+			  provider = "postgresql"
+			  url      = env("DATABASE_URL")
+			}
+
+			model User {
+			  id String    @id @default(uuid(7)) @db.Uuid
+			  at DateTime? @db.Timestamptz
+			}
+			`),
+		},
+		{
+			name: "explicit join model of compound id",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Pair
+				  * @left: integer
+				  * @right: integer
+				  * tags: *Tag.id[]
+				* table: Tag
+				  * @id
+				`),
+				dialect: PostgreSQL,
+			},
+			want: TrimIndent(t, `
+			datasource db {
+			  provider = "postgresql"
+			  url      = env("DATABASE_URL")
+			}
+
+			model Pair {
+			  left      Int
+			  right     Int
+			  Pair_tags Pair_tags[]
+
+			  @@id([left, right])
+			}
+
+			model Tag {
+			  id        Int         @id @default(autoincrement())
+			  Pair_tags Pair_tags[]
+			}
+
+			model Pair_tags {
+			  id         Int   @id @default(autoincrement())
+			  Pair_left  Int?
+			  Pair_right Int?
+			  Tag_id     Int?
+			  Pair       Pair? @relation(fields: [Pair_left, Pair_right], references: [left, right])
+			  Tag        Tag?  @relation(fields: [Tag_id], references: [id])
+			}
+			`),
+		},
+		{
+			name: "SQL Server",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Tag
+				  * @id
+				  * $name: string
+				  * code: char(8)
+				  * note: text?
+				`),
+				dialect: SQLServer,
+			},
+			want: TrimIndent(t, `
+			datasource db {
+			  provider = "sqlserver"
+			  url      = env("DATABASE_URL")
+			}
+
+			model Tag {
+			  id   Int     @id @default(autoincrement())
+			  name String  @unique @db.NVarChar(450)
+			  code String  @db.NChar(8)
+			  note String? @db.NVarChar(Max)
+			}
+			`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := Parse(strings.NewReader(tt.args.src))
+			assert.NoError(t, err)
+			var out bytes.Buffer
+			err = DumpPrisma(&out, tables, tt.args.dialect, tt.args.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want+"\n", out.String())
+		})
+	}
+}
+
+func TestDumpPrisma_Unsupported(t *testing.T) {
+	tables, err := Parse(strings.NewReader("* table: User\n  * @id\n"))
+	assert.NoError(t, err)
+	err = DumpPrisma(&bytes.Buffer{}, tables, Oracle)
+	assert.Error(t, err)
+}
@@ -6,85 +6,600 @@ import (
 	"strings"
 )
 
-func DumpSQL(w io.Writer, tables []*Table, d Dialect) error {
-	rels, err := fixRelations(tables, d)
+// sqlWriter writes statements separated by blank lines and terminated by the dialect's terminator.
+type sqlWriter struct {
+	w     io.Writer
+	d     mappedDialect
+	o     *option
+	count int
+	// teardown means DROP statements are written by WithDropTables.
+	teardown bool
+}
+
+func (s *sqlWriter) statement(format string, args ...any) {
+	if s.count != 0 {
+		io.WriteString(s.w, "\n\n")
+	}
+	s.begin()
+	fmt.Fprintf(s.w, format, args...)
+	io.WriteString(s.w, ";"+s.separator())
+	s.end()
+	s.count++
+}
+
+// block writes the statement that has its own terminator like PL/SQL blocks.
+func (s *sqlWriter) block(text string) {
+	if s.count != 0 {
+		io.WriteString(s.w, "\n\n")
+	}
+	if s.o.gooseStatements {
+		// "/" runs the block on SQL*Plus, but goose sends the text between the annotations as is
+		text = strings.TrimSuffix(text, "\n/")
+	}
+	s.begin()
+	io.WriteString(s.w, text+s.separator())
+	s.end()
+	s.count++
+}
+
+// begin and end surround the statement by the annotations of goose if the option is specified.
+func (s *sqlWriter) begin() {
+	if s.o.gooseStatements {
+		io.WriteString(s.w, "-- +goose StatementBegin\n")
+	}
+}
+
+func (s *sqlWriter) end() {
+	if s.o.gooseStatements {
+		io.WriteString(s.w, "\n-- +goose StatementEnd")
+	}
+}
+
+// separator returns the batch separator of the dialect unless WithoutBatchSeparator is specified.
+func (s *sqlWriter) separator() string {
+	if s.o.noBatchSeparator {
+		return ""
+	}
+	return s.d.BatchSeparator()
+}
+
+// sequence writes a sequence for the auto-increment key if the dialect implements it by a sequence.
+func (s *sqlWriter) sequence(table, column string) {
+	if s.d.Capabilities().SequenceTrigger || s.d.Capabilities().SequenceDefault {
+		// the clause is put before the name because the dialect formats the statement
+		s.statement("%s", s.d.CreateSequence(s.ifNotExists(s.d.Capabilities().NoCreateIfNotExists)+s.q(sequenceName(s.d.Dialect, table, column))))
+	}
+}
+
+// sequenceDefault returns DEFAULT clause for the auto-increment key if the dialect needs it.
+func (s *sqlWriter) sequenceDefault(table, column string) string {
+	if !s.d.Capabilities().SequenceDefault {
+		return ""
+	}
+	return s.d.SequenceDefault(sequenceName(s.d.Dialect, table, column))
+}
+
+// trigger writes a trigger that fills the auto-increment key if the dialect needs it.
+func (s *sqlWriter) trigger(table, column string) {
+	if !s.d.Capabilities().SequenceTrigger {
+		return
+	}
+	s.block(fmt.Sprintf("CREATE OR REPLACE TRIGGER %s\nBEFORE INSERT ON %s\nFOR EACH ROW\nWHEN (new.%s IS NULL)\nBEGIN\n\t:new.%s := %s.NEXTVAL;\nEND;\n/",
+		s.q(triggerName(s.d.Dialect, table, column)), s.q(table), s.q(column), s.q(column), s.q(sequenceName(s.d.Dialect, table, column))))
+}
+
+// comment writes the statement that the dialect doesn't support as a comment.
+func (s *sqlWriter) comment(format string, args ...any) {
+	if s.count != 0 {
+		io.WriteString(s.w, "\n\n")
+	}
+	io.WriteString(s.w, "-- "+fmt.Sprintf(format, args...)+";")
+	s.count++
+}
+
+// note writes the comment that isn't a statement.
+func (s *sqlWriter) note(format string, args ...any) {
+	if s.count != 0 {
+		io.WriteString(s.w, "\n\n")
+	}
+	io.WriteString(s.w, "-- "+fmt.Sprintf(format, args...))
+	s.count++
+}
+
+// ifNotExists returns "IF NOT EXISTS " if WithIfNotExists is specified and the dialect has it.
+func (s *sqlWriter) ifNotExists(unsupported bool) string {
+	if !s.o.ifNotExists || unsupported {
+		return ""
+	}
+	return "IF NOT EXISTS "
+}
+
+// ifExists returns "IF EXISTS " for the DROP statements of WithDropTables if the dialect has it.
+func (s *sqlWriter) ifExists() string {
+	if !s.teardown || s.d.Capabilities().NoDropIfExists {
+		return ""
+	}
+	return "IF EXISTS "
+}
+
+// q quotes the identifier.
+func (s *sqlWriter) q(name string) string {
+	return s.d.QuoteIdentifier(name)
+}
+
+// ql quotes the identifiers and joins them by comma.
+func (s *sqlWriter) ql(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = s.d.QuoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (s *sqlWriter) constraint(name string) string {
+	if name == "" {
+		return ""
+	}
+	return "CONSTRAINT " + s.q(name) + " "
+}
+
+// table returns the quoted table name qualified by the dataset if specified.
+func (s *sqlWriter) table(name string) string {
+	if s.d.dataset != "" {
+		return s.q(s.d.dataset) + "." + s.q(name)
+	}
+	return s.q(name)
+}
+
+func (s *sqlWriter) primaryKey(name string, pks []string) string {
+	if s.d.Capabilities().UnenforcedConstraint {
+		return fmt.Sprintf("PRIMARY KEY(%s) NOT ENFORCED", s.ql(pks))
+	}
+	return fmt.Sprintf("%sPRIMARY KEY(%s)", s.constraint(name), s.ql(pks))
+}
+
+func (s *sqlWriter) foreignKey(fk *foreignKey, name string) string {
+	var suffix string
+	if s.d.Capabilities().UnenforcedConstraint {
+		suffix = " NOT ENFORCED"
+	}
+	return fmt.Sprintf("%sFOREIGN KEY(%s) REFERENCES %s(%s)%s", s.constraint(name), s.ql(fk.Columns), s.table(fk.RefTable), s.ql(fk.RefColumns), suffix)
+}
+
+// unenforcedForeignKey writes the foreign key as a comment for the dialect that doesn't enforce foreign keys.
+func (s *sqlWriter) unenforcedForeignKey(fk *foreignKey, name string) {
+	s.comment("ALTER TABLE %s ADD %s (%s doesn't enforce foreign keys)", s.table(fk.Table), s.foreignKey(fk, name), s.d.Dialect)
+}
+
+// tableOptions returns the clauses after the column list that are specified by the table annotations.
+func (s *sqlWriter) tableOptions(annotations map[string]string, pks []string) string {
+	keys := make([]string, len(pks))
+	for i, pk := range pks {
+		keys[i] = s.q(pk)
+	}
+	return s.d.TableOptions(annotations, keys)
+}
+
+func DumpSQL(w io.Writer, tables []*Table, d Dialect, opts ...Option) error {
+	o := newOption(opts)
+	md := o.dialect(d)
+	d = md.Dialect
+	rels, err := fixRelations(tables)
 	if err != nil {
 		return err
 	}
 
+	s := &sqlWriter{w: w, d: md, o: o}
+	ordered, fks := sortTables(tables, o.sourceOrder)
+	tablePKs := primaryKeys(tables)
+	var deferred []*foreignKey
+	var drops []string
+	if o.dropTables && s.dropsForeignKeys() {
+		for _, t := range ordered {
+			for _, fk := range fks[t.Name] {
+				if !fk.Deferred {
+					continue
+				}
+				drop, err := s.dropDeferredForeignKey(fk)
+				if err != nil {
+					return err
+				}
+				drops = append(drops, drop)
+			}
+		}
+	}
+
 	fmt.Fprintf(w, d.EnableForeignKey(len(rels) > 0))
 
+	if o.ifNotExists && !o.dropTables {
+		s.ifNotExistsWarning(tables, fks)
+	}
+	if o.dropTables {
+		s.dropTables(tables, ordered, fks, drops)
+	}
+
 	// table definition
-	for i, t := range tables {
-		if i != 0 {
-			fmt.Fprintf(w, "\n\n")
-		}
-		fmt.Fprintf(w, "CREATE TABLE %s(\n", t.Name)
-		var rows []string
-		fkSrcs := make(map[string][]string)
-		fkDests := make(map[string][]string)
-		var pks []string
-		var fkTables []string
+	for _, t := range ordered {
+		deferred = append(deferred, s.createTable(t, fks[t.Name], tablePKs)...)
+	}
+
+	// associative entity
+	for _, t := range tables {
 		for _, c := range t.Columns {
-			if c.PrimaryKey {
-				pks = append(pks, c.Name)
-				rows = append(rows, fmt.Sprintf("\t%s %s", c.Name, d.PrimaryKeySQLType(c.Type, c.AutoIncrement)))
-			} else if c.AssociativeEntity {
-				// do nothing
-			} else if c.Nullable {
-				rows = append(rows, fmt.Sprintf("\t%s %s", c.Name, d.TypeConversion(c.Type)))
-			} else {
-				rows = append(rows, fmt.Sprintf("\t%s %s NOT NULL", c.Name, d.TypeConversion(c.Type)))
+			if c.LinkTable != "" && c.AssociativeEntity {
+				s.createAssociativeTable(t, c)
 			}
-			if c.LinkTable != "" && !c.AssociativeEntity {
-				fkSrcs[c.LinkTable] = append(fkSrcs[c.LinkTable], c.Name)
-				fkDests[c.LinkTable] = append(fkDests[c.LinkTable], c.LinkColumn)
-				fkTables = append(fkTables, c.LinkTable)
+		}
+	}
+
+	// foreign keys in cycles
+	for _, fk := range deferred {
+		s.addForeignKey(fk)
+	}
+
+	return nil
+}
+
+// dropTables writes DROP statements of the tables in reverse order of creation.
+// ordered and fks are the result of sortTables, and drops are the statements that drop the foreign keys in cycles.
+// Foreign key checks are disabled instead if the dialect can.
+func (s *sqlWriter) dropTables(tables, ordered []*Table, fks map[string][]*foreignKey, drops []string) {
+	caps := s.d.Capabilities()
+	s.teardown = true
+	defer func() {
+		s.teardown = false
+	}()
+	var enable string
+	if disable, on := s.d.ForeignKeyChecks(); disable != "" && hasDeferred(fks) {
+		s.statement("%s", disable)
+		enable = on
+	}
+	for _, drop := range drops {
+		s.statement("%s", drop)
+	}
+	if caps.NoDropTableWithIndex {
+		for _, t := range tables {
+			for _, c := range t.Columns {
+				if c.Index {
+					s.dropIndex(t.Name, c.Name)
+				}
 			}
 		}
-		if len(pks) > 0 {
-			rows = append(rows, fmt.Sprintf("\tPRIMARY KEY(%s)", strings.Join(pks, ", ")))
+	}
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if c.LinkTable != "" && c.AssociativeEntity {
+				s.dropAssociativeTable(t, c)
+			}
 		}
-		for _, t := range fkTables {
-			rows = append(rows, fmt.Sprintf("\tFOREIGN KEY(%s) REFERENCES %s(%s)", strings.Join(fkSrcs[t], ", "), t, strings.Join(fkDests[t], ", ")))
+	}
+	for i := len(ordered) - 1; i >= 0; i-- {
+		s.dropTable(ordered[i])
+	}
+	if enable != "" {
+		s.statement("%s", enable)
+	}
+}
+
+func hasDeferred(fks map[string][]*foreignKey) bool {
+	for _, list := range fks {
+		for _, fk := range list {
+			if fk.Deferred {
+				return true
+			}
 		}
-		fmt.Fprintf(w, "%s\n);", strings.Join(rows, ",\n"))
+	}
+	return false
+}
+
+// dropsForeignKeys returns true if WithDropTables drops the foreign keys in cycles one by one before dropping the tables.
+func (s *sqlWriter) dropsForeignKeys() bool {
+	caps := s.d.Capabilities()
+	disable, _ := s.d.ForeignKeyChecks()
+	return s.o.dropTables && s.d.DropTableCascade() == "" && disable == "" &&
+		!caps.LazyForeignKeyCheck && !caps.NoForeignKey && !caps.NoAlterForeignKey
+}
+
+// deferredForeignKeyName returns the name of the foreign key in a cycle. The foreign keys that WithDropTables drops by name
+// are named by StandardNamingConvention if neither the naming convention nor the database names them.
+func (s *sqlWriter) deferredForeignKeyName(fk *foreignKey) string {
+	d := s.d.Dialect
+	name := s.o.naming.foreignKey(d, fk)
+	if name == "" && s.dropsForeignKeys() && d.ConstraintNaming().ForeignKey == "" {
+		name = expandName(d, StandardNamingConvention.ForeignKey, fk.Table, fk.Columns, fk.RefTable, fk.RefColumns)
+	}
+	return name
+}
+
+// foreignKeyRef returns the reference of the foreign key for the conditional statements.
+// The name that the database gives is used if the script doesn't name it.
+func (s *sqlWriter) foreignKeyRef(fk *foreignKey, name string) ForeignKeyRef {
+	d := s.d.Dialect
+	if name == "" {
+		name = d.ConstraintNaming().foreignKey(d, fk)
+	}
+	return ForeignKeyRef{Table: fk.Table, Columns: fk.Columns, RefTable: fk.RefTable, Name: name}
+}
+
+// dropDeferredForeignKey returns the statement that drops the foreign key in a cycle if it exists
+// so that the script can run on both new and existing databases.
+func (s *sqlWriter) dropDeferredForeignKey(fk *foreignKey) (string, error) {
+	name := s.deferredForeignKeyName(fk)
+	ref := s.foreignKeyRef(fk, name)
+	statement := fmt.Sprintf("ALTER TABLE %s %s", s.table(fk.Table), s.d.DropForeignKey(s.q(ref.Name)))
+	result := s.d.IfForeignKey(ref, true, statement)
+	if result == "" {
+		return "", fmt.Errorf("%s can't drop the foreign key %s(%s) in the cycle only if it exists", s.d.Dialect, fk.Table, strings.Join(fk.Columns, ", "))
+	}
+	return result, nil
+}
 
+// ifNotExistsWarning writes the warning of WithIfNotExists if the dialect can't write some statements conditionally.
+func (s *sqlWriter) ifNotExistsWarning(tables []*Table, fks map[string][]*foreignKey) {
+	caps := s.d.Capabilities()
+	var missing []string
+	if caps.NoCreateIfNotExists {
+		missing = append(missing, "CREATE TABLE IF NOT EXISTS")
+	}
+	hasIndex := false
+	for _, t := range tables {
 		for _, c := range t.Columns {
-			if c.Index {
-				fmt.Fprintf(w, "\n\nCREATE UNIQUE INDEX INDEX_%s_%s ON %s(%s);", t.Name, c.Name, t.Name, c.Name)
+			hasIndex = hasIndex || c.Index
+		}
+	}
+	if caps.NoIndexIfNotExists && hasIndex && !caps.NoUniqueIndex {
+		missing = append(missing, "CREATE INDEX IF NOT EXISTS")
+	}
+	guarded := true
+	for _, list := range fks {
+		for _, fk := range list {
+			if fk.Deferred && s.d.IfForeignKey(s.foreignKeyRef(fk, s.deferredForeignKeyName(fk)), false, "ALTER TABLE") == "" {
+				guarded = false
 			}
 		}
 	}
+	if !guarded && !caps.LazyForeignKeyCheck && !caps.NoForeignKey && !caps.NoAlterForeignKey {
+		missing = append(missing, "ADD FOREIGN KEY IF NOT EXISTS")
+	}
+	if len(missing) > 0 {
+		s.note("WARNING: %s doesn't have %s, so the script can't run on the existing tables", s.d.Dialect, strings.Join(missing, " and "))
+	}
+}
 
-	// associative entity
+// primaryKeys returns the primary key columns of the tables.
+func primaryKeys(tables []*Table) map[string][]string {
+	result := make(map[string][]string)
 	for _, t := range tables {
-		var pks []string
-		var pkTypes []string
 		for _, c := range t.Columns {
 			if c.PrimaryKey {
-				pks = append(pks, c.Name)
-				pkTypes = append(pkTypes, c.Type)
+				result[t.Name] = append(result[t.Name], c.Name)
 			}
 		}
+	}
+	return result
+}
 
-		for _, c := range t.Columns {
-			if c.LinkTable != "" && c.AssociativeEntity {
-				fmt.Fprintf(w, "\n\nCREATE TABLE %s_%s(\n", t.Name, c.Name)
-				var rows []string
-				rows = append(rows, fmt.Sprintf("\tid %s PRIMARY KEY", d.PrimaryKeySQLType("", true)))
-				var fks []string
-				for i, pk := range pks {
-					rows = append(rows, fmt.Sprintf("\t%s_%s %s", t.Name, pk, d.PrimaryKeyBaseType(pkTypes[i])))
-					fks = append(fks, t.Name+"_"+pk)
-				}
-				rows = append(rows, fmt.Sprintf("\t%s_%s %s", c.LinkTable, c.LinkColumn, d.PrimaryKeyBaseType(c.Type)))
-				rows = append(rows, fmt.Sprintf("\tFOREIGN KEY(%s) REFERENCES %s(%s)", strings.Join(fks, ", "), t.Name, strings.Join(pks, ", ")))
-				rows = append(rows, fmt.Sprintf("\tFOREIGN KEY(%s_%s) REFERENCES %s(%s)", c.LinkTable, c.LinkColumn, c.LinkTable, c.LinkColumn))
-				fmt.Fprintf(w, "%s\n);", strings.Join(rows, ",\n"))
+// createTable writes the table with its indexes. fks are the foreign keys of the table.
+// It returns the deferred foreign keys that should be added after all tables are created.
+// tablePKs are the primary keys of the tables to find the parent table to be interleaved in.
+func (s *sqlWriter) createTable(t *Table, fks []*foreignKey, tablePKs map[string][]string) []*foreignKey {
+	o, md, d := s.o, s.d, s.d.Dialect
+	caps := md.Capabilities()
+	var deferred []*foreignKey
+	var rows []string
+	var pks []string
+	var pkColumns []*Column
+	for _, c := range t.Columns {
+		if c.PrimaryKey {
+			pks = append(pks, c.Name)
+			pkColumns = append(pkColumns, c)
+		}
+		if c.PrimaryKey && c.AutoIncrement {
+			s.sequence(t.Name, c.Name)
+		}
+	}
+	var inlineKey string
+	var dropModifier bool
+	if caps.InlineAutoIncrementKey {
+		// the modifier like AUTOINCREMENT is only allowed on the single primary key of rowid tables
+		if len(pkColumns) == 1 && pkColumns[0].AutoIncrement && !annotationFlag(t.Annotations, "without_rowid") {
+			inlineKey = md.inlineKey(pkColumns[0], s.constraint(o.naming.primaryKey(d, t.Name, pks)))
+		} else {
+			dropModifier = true
+		}
+	}
+	if dropModifier && len(pkColumns) == 1 && pkColumns[0].AutoIncrement {
+		// inserts without the key fail, while composite keys are documented as plain keys
+		s.note("WARNING: %s.%s isn't auto-incremented because %s doesn't have AUTOINCREMENT on WITHOUT ROWID tables", t.Name, pkColumns[0].Name, d)
+	}
+	sqlType := s.sqlType(t)
+	for _, c := range t.Columns {
+		if c.PrimaryKey && inlineKey != "" {
+			rows = append(rows, fmt.Sprintf("\t%s %s", s.q(c.Name), inlineKey))
+		} else if c.PrimaryKey && c.AutoIncrement && dropModifier {
+			rows = append(rows, fmt.Sprintf("\t%s %s", s.q(c.Name), sqlType(md.baseType(c))))
+		} else if c.PrimaryKey {
+			var def string
+			if c.AutoIncrement {
+				def = s.sequenceDefault(t.Name, c.Name)
 			}
+			rows = append(rows, fmt.Sprintf("\t%s %s%s", s.q(c.Name), sqlType(md.keyType(c)), def))
+		} else if c.AssociativeEntity {
+			// do nothing
+		} else {
+			rows = append(rows, fmt.Sprintf("\t%s %s", s.q(c.Name), s.columnDefinition(c, sqlType)))
 		}
 	}
+	var suffix string
+	if len(pks) > 0 && !caps.OrderByKey && inlineKey == "" {
+		if caps.PrimaryKeyAfterColumns {
+			suffix = fmt.Sprintf(" PRIMARY KEY(%s)", s.ql(pks))
+		} else {
+			rows = append(rows, "\t"+s.primaryKey(o.naming.primaryKey(d, t.Name, pks), pks))
+		}
+	}
+	suffix += s.tableOptions(t.Annotations, pks)
+	var parent *foreignKey
+	if caps.Interleave && !t.Independent {
+		parent = interleaveParent(pks, fks, tablePKs)
+	}
+	if parent != nil {
+		suffix += fmt.Sprintf(",\n\tINTERLEAVE IN PARENT %s", s.q(parent.RefTable))
+	}
+	var unenforced []*foreignKey
+	for _, fk := range fks {
+		if fk == parent {
+			continue
+		}
+		if caps.NoForeignKey {
+			unenforced = append(unenforced, fk)
+			continue
+		}
+		if fk.Deferred && !caps.LazyForeignKeyCheck {
+			deferred = append(deferred, fk)
+			continue
+		}
+		rows = append(rows, "\t"+s.foreignKey(fk, o.naming.foreignKey(d, fk)))
+	}
+	s.statement("CREATE TABLE %s%s(\n%s\n)%s", s.ifNotExists(caps.NoCreateIfNotExists), s.table(t.Name), strings.Join(rows, ",\n"), suffix)
+	for _, fk := range unenforced {
+		s.unenforcedForeignKey(fk, o.naming.foreignKey(d, fk))
+	}
+
+	for _, c := range t.Columns {
+		if c.PrimaryKey && c.AutoIncrement {
+			s.trigger(t.Name, c.Name)
+		}
+	}
+	for _, c := range t.Columns {
+		if c.Index {
+			s.createIndex(t.Name, c.Name)
+		}
+	}
+	return deferred
+}
+
+// sqlType returns the function that converts the SQL types for the table like STRICT tables of SQLite.
+func (s *sqlWriter) sqlType(t *Table) func(string) string {
+	if s.d.Capabilities().StrictTables && annotationFlag(t.Annotations, "strict") {
+		return strictType
+	}
+	return func(t string) string { return t }
+}
 
+// columnDefinition returns the type and the nullability of the column that isn't a primary key like "TEXT NOT NULL".
+func (s *sqlWriter) columnDefinition(c *Column, sqlType func(string) string) string {
+	t := s.d.columnType(c)
+	if c.Nullable {
+		return s.d.NullableType(sqlType(t))
+	}
+	return sqlType(t) + " NOT NULL"
+}
+
+// createIndex writes the unique index of the column.
+func (s *sqlWriter) createIndex(table, column string) {
+	format := "CREATE UNIQUE INDEX %s%s ON %s(%s)"
+	args := []any{s.ifNotExists(s.d.Capabilities().NoIndexIfNotExists), s.q(s.o.naming.unique(s.d.Dialect, table, []string{column})), s.table(table), s.q(column)}
+	if !s.d.Capabilities().NoUniqueIndex {
+		s.statement(format, args...)
+	} else {
+		s.comment(format+" (%s doesn't support unique indexes)", append(args, s.d.Dialect)...)
+	}
+}
+
+// createAssociativeTable writes the table of the associative entity column c of the table t.
+func (s *sqlWriter) createAssociativeTable(t *Table, c *Column) {
+	o, md, d := s.o, s.d, s.d.Dialect
+	caps := md.Capabilities()
+	var pks []string
+	var pkColumns []*Column
+	for _, pk := range t.Columns {
+		if pk.PrimaryKey {
+			pks = append(pks, pk.Name)
+			pkColumns = append(pkColumns, pk)
+		}
+	}
+	associativeKey := &Column{Name: "id", PrimaryKey: true, AutoIncrement: true}
+	name := t.Name + "_" + c.Name
+	s.sequence(name, "id")
+	var rows []string
+	var suffix string
+	pkName := o.naming.primaryKey(d, name, []string{"id"})
+	if caps.InlineAutoIncrementKey {
+		rows = append(rows, fmt.Sprintf("\t%s %s", s.q("id"), md.inlineKey(associativeKey, s.constraint(pkName))))
+		pkName = ""
+	} else if caps.OrderByKey {
+		rows = append(rows, fmt.Sprintf("\t%s %s", s.q("id"), md.keyType(associativeKey)))
+		suffix = s.tableOptions(nil, []string{"id"})
+		pkName = ""
+	} else if caps.PrimaryKeyAfterColumns {
+		rows = append(rows, fmt.Sprintf("\t%s %s%s", s.q("id"), md.keyType(associativeKey), s.sequenceDefault(name, "id")))
+		suffix = fmt.Sprintf(" PRIMARY KEY(%s)", s.q("id"))
+		pkName = ""
+	} else if pkName == "" && !caps.UnenforcedConstraint {
+		rows = append(rows, fmt.Sprintf("\t%s %s%s PRIMARY KEY", s.q("id"), md.keyType(associativeKey), s.sequenceDefault(name, "id")))
+	} else {
+		rows = append(rows, fmt.Sprintf("\t%s %s%s", s.q("id"), md.keyType(associativeKey), s.sequenceDefault(name, "id")))
+	}
+	var fks []string
+	for i, pk := range pks {
+		rows = append(rows, fmt.Sprintf("\t%s %s", s.q(t.Name+"_"+pk), md.baseType(pkColumns[i])))
+		fks = append(fks, t.Name+"_"+pk)
+	}
+	rows = append(rows, fmt.Sprintf("\t%s %s", s.q(c.LinkTable+"_"+c.LinkColumn), md.baseType(c)))
+	if pkName != "" || caps.UnenforcedConstraint {
+		rows = append(rows, "\t"+s.primaryKey(pkName, []string{"id"}))
+	}
+	links := []*foreignKey{
+		{Table: name, Columns: fks, RefTable: t.Name, RefColumns: pks},
+		{Table: name, Columns: []string{c.LinkTable + "_" + c.LinkColumn}, RefTable: c.LinkTable, RefColumns: []string{c.LinkColumn}},
+	}
+	if !caps.NoForeignKey {
+		for _, fk := range links {
+			rows = append(rows, "\t"+s.foreignKey(fk, o.naming.foreignKey(d, fk)))
+		}
+	}
+	s.statement("CREATE TABLE %s%s(\n%s\n)%s", s.ifNotExists(caps.NoCreateIfNotExists), s.table(name), strings.Join(rows, ",\n"), suffix)
+	if caps.NoForeignKey {
+		for _, fk := range links {
+			s.unenforcedForeignKey(fk, o.naming.foreignKey(d, fk))
+		}
+	}
+	s.trigger(name, "id")
+}
+
+// addForeignKey writes ALTER TABLE that adds the foreign key to the existing table.
+// The foreign key is added only if it doesn't exist for WithIfNotExists if the dialect can check it.
+func (s *sqlWriter) addForeignKey(fk *foreignKey) {
+	name := s.deferredForeignKeyName(fk)
+	if s.d.Capabilities().NoForeignKey {
+		s.unenforcedForeignKey(fk, name)
+	} else if !s.d.Capabilities().NoAlterForeignKey {
+		statement := fmt.Sprintf("ALTER TABLE %s ADD %s", s.table(fk.Table), s.foreignKey(fk, name))
+		if s.o.ifNotExists && !s.o.dropTables {
+			if guarded := s.d.IfForeignKey(s.foreignKeyRef(fk, name), false, statement); guarded != "" {
+				statement = guarded
+			}
+		}
+		s.statement("%s", statement)
+	} else {
+		// the database doesn't check the reference without the foreign key
+		s.note("WARNING: %s(%s) doesn't refer %s because %s can't add foreign keys in cycles", fk.Table, strings.Join(fk.Columns, ", "), fk.RefTable, s.d.Dialect)
+		s.comment("ALTER TABLE %s ADD %s (%s can't add foreign keys to existing tables)", s.table(fk.Table), s.foreignKey(fk, name), s.d.Dialect)
+	}
+}
+
+// interleaveParent returns the foreign key to the parent table that the table can be interleaved in.
+// The parent's primary key columns should be the prefix of the table's primary key with the same names.
+func interleaveParent(pks []string, fks []*foreignKey, tablePKs map[string][]string) *foreignKey {
+	for _, fk := range fks {
+		parentPKs := tablePKs[fk.RefTable]
+		if len(parentPKs) == 0 || len(parentPKs) > len(pks) || !equalStrings(fk.RefColumns, parentPKs) {
+			continue
+		}
+		if equalStrings(fk.Columns, parentPKs) && equalStrings(pks[:len(parentPKs)], parentPKs) {
+			return fk
+		}
+	}
 	return nil
 }
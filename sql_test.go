@@ -16,7 +16,9 @@ func init() {
 
 func TestSQL(t *testing.T) {
 	type args struct {
-		src string
+		src     string
+		dialect Dialect
+		opts    []Option
 	}
 	tests := []struct {
 		name    string
@@ -54,17 +56,17 @@ func TestSQL(t *testing.T) {
 				`),
 			},
 			want: TrimIndent(t, `
+			CREATE TABLE Job(
+				id SERIAL,
+				PRIMARY KEY(id)
+			);
+
 			CREATE TABLE User(
 				id SERIAL,
 				job INTEGER NOT NULL,
 				PRIMARY KEY(id),
 				FOREIGN KEY(job) REFERENCES Job(id)
 			);
-
-			CREATE TABLE Job(
-				id SERIAL,
-				PRIMARY KEY(id)
-			);
 			`),
 		},
 		{
@@ -165,17 +167,1155 @@ func TestSQL(t *testing.T) {
 			CREATE UNIQUE INDEX INDEX_User_email ON User(email);
 			`),
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			w := &bytes.Buffer{}
-			tables, err := Parse(strings.NewReader(tt.args.src))
-			assert.NoError(t, err)
-			if err != nil {
-				return
-			}
-			DumpSQL(w, tables, PostgreSQL)
-			assert.Equal(t, tt.want, w.String())
-		})
-	}
+		{
+			name: "keep source order",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * job: *Job.id
+				* table: Job
+				  * @id
+				`),
+				opts: []Option{WithSourceOrder()},
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE User(
+				id SERIAL,
+				job INTEGER NOT NULL,
+				PRIMARY KEY(id)
+			);
+
+			CREATE TABLE Job(
+				id SERIAL,
+				PRIMARY KEY(id)
+			);
+
+			ALTER TABLE User ADD FOREIGN KEY(job) REFERENCES Job(id);
+			`),
+		},
+		{
+			name: "self reference",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Employee
+				  * @id
+				  * boss: *Employee.id?
+				`),
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE Employee(
+				id SERIAL,
+				boss INTEGER,
+				PRIMARY KEY(id)
+			);
+
+			ALTER TABLE Employee ADD FOREIGN KEY(boss) REFERENCES Employee(id);
+			`),
+		},
+		{
+			name: "cycle",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Department
+				  * @id
+				  * manager: *Employee.id?
+				* table: Employee
+				  * @id
+				  * department: *Department.id
+				* table: Office
+				  * @id
+				  * department: *Department.id
+				`),
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE Department(
+				id SERIAL,
+				manager INTEGER,
+				PRIMARY KEY(id)
+			);
+
+			CREATE TABLE Employee(
+				id SERIAL,
+				department INTEGER NOT NULL,
+				PRIMARY KEY(id),
+				FOREIGN KEY(department) REFERENCES Department(id)
+			);
+
+			CREATE TABLE Office(
+				id SERIAL,
+				department INTEGER NOT NULL,
+				PRIMARY KEY(id),
+				FOREIGN KEY(department) REFERENCES Department(id)
+			);
+
+			ALTER TABLE Department ADD FOREIGN KEY(manager) REFERENCES Employee(id);
+			`),
+		},
+		{
+			name: "standard naming convention",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * $email: string
+				  * job: *Job.id
+				  * skills: *Skill.id[]
+				* table: Job
+				  * @id
+				* table: Skill
+				  * @id
+				`),
+				opts: []Option{WithNamingConvention(StandardNamingConvention)},
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE Job(
+				id SERIAL,
+				CONSTRAINT pk_Job PRIMARY KEY(id)
+			);
+
+			CREATE TABLE User(
+				id SERIAL,
+				email TEXT NOT NULL,
+				job INTEGER NOT NULL,
+				CONSTRAINT pk_User PRIMARY KEY(id),
+				CONSTRAINT fk_User_job_Job FOREIGN KEY(job) REFERENCES Job(id)
+			);
+
+			CREATE UNIQUE INDEX uq_User_email ON User(email);
+
+			CREATE TABLE Skill(
+				id SERIAL,
+				CONSTRAINT pk_Skill PRIMARY KEY(id)
+			);
+
+			CREATE TABLE User_skills(
+				id SERIAL,
+				User_id INTEGER,
+				Skill_id INTEGER,
+				CONSTRAINT pk_User_skills PRIMARY KEY(id),
+				CONSTRAINT fk_User_skills_User_id_User FOREIGN KEY(User_id) REFERENCES User(id),
+				CONSTRAINT fk_User_skills_Skill_id_Skill FOREIGN KEY(Skill_id) REFERENCES Skill(id)
+			);
+			`),
+		},
+		{
+			name: "named foreign key in cycle",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Employee
+				  * @id
+				  * boss: *Employee.id?
+				`),
+				opts: []Option{WithNamingConvention(NamingConvention{ForeignKey: "{table}_{columns}_fkey"})},
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE Employee(
+				id SERIAL,
+				boss INTEGER,
+				PRIMARY KEY(id)
+			);
+
+			ALTER TABLE Employee ADD CONSTRAINT Employee_boss_fkey FOREIGN KEY(boss) REFERENCES Employee(id);
+			`),
+		},
+		{
+			name: "MySQL foreign keys match SERIAL",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * job: *Job.id
+				  * skills: *Skill.id[]
+				* table: Job
+				  * @id
+				* table: Skill
+				  * @id
+				`),
+				dialect: MySQL,
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE Job(
+				id SERIAL,
+				PRIMARY KEY(id)
+			);
+
+			CREATE TABLE User(
+				id SERIAL,
+				job BIGINT UNSIGNED NOT NULL,
+				PRIMARY KEY(id),
+				FOREIGN KEY(job) REFERENCES Job(id)
+			);
+
+			CREATE TABLE Skill(
+				id SERIAL,
+				PRIMARY KEY(id)
+			);
+
+			CREATE TABLE User_skills(
+				id SERIAL PRIMARY KEY,
+				User_id BIGINT UNSIGNED,
+				Skill_id BIGINT UNSIGNED,
+				FOREIGN KEY(User_id) REFERENCES User(id),
+				FOREIGN KEY(Skill_id) REFERENCES Skill(id)
+			);
+			`),
+		},
+		{
+			name: "PostgreSQL identity strategies",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * !identity: uuid
+				  * @id
+				  * job: *Job.id?
+				  * skills: *Skill.id[]
+				* table: Job
+				  * @id
+				* table: Skill
+				  * !identity: uuidv7:uuid_generate_v7()
+				  * @id
+				* table: Post
+				  * @id
+				  * author: *User.id
+				`),
+				opts: []Option{WithIdentity("identity")},
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE Job(
+				id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+				PRIMARY KEY(id)
+			);
+
+			CREATE TABLE User(
+				id UUID DEFAULT gen_random_uuid(),
+				job BIGINT,
+				PRIMARY KEY(id),
+				FOREIGN KEY(job) REFERENCES Job(id)
+			);
+
+			CREATE TABLE Skill(
+				id UUID DEFAULT uuid_generate_v7(),
+				PRIMARY KEY(id)
+			);
+
+			CREATE TABLE Post(
+				id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+				author UUID NOT NULL,
+				PRIMARY KEY(id),
+				FOREIGN KEY(author) REFERENCES User(id)
+			);
+
+			CREATE TABLE User_skills(
+				id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+				User_id UUID,
+				Skill_id UUID,
+				FOREIGN KEY(User_id) REFERENCES User(id),
+				FOREIGN KEY(Skill_id) REFERENCES Skill(id)
+			);
+			`),
+		},
+		{
+			name: "SQL Server key types",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Country
+				  * @code: string
+				  * name: string
+				  * note: text?
+				* table: City
+				  * @id
+				  * country: *Country.code
+				`),
+				dialect: SQLServer,
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE [Country](
+				[code] NVARCHAR(450),
+				[name] NVARCHAR(MAX) NOT NULL,
+				[note] NVARCHAR(MAX),
+				PRIMARY KEY([code])
+			);
+			GO
+
+			CREATE TABLE [City](
+				[id] INTEGER IDENTITY(1,1),
+				[country] NVARCHAR(450) NOT NULL,
+				PRIMARY KEY([id]),
+				FOREIGN KEY([country]) REFERENCES [Country]([code])
+			);
+			GO
+			`),
+		},
+		{
+			name: "SQL Server",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * $email: string
+				  * icon: blob?
+				  * jobs: *Job.id[]
+				* table: Job
+				  * @id
+				  * name: varchar(30)
+				`),
+				dialect: SQLServer,
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE [User](
+				[id] INTEGER IDENTITY(1,1),
+				[email] NVARCHAR(450) NOT NULL,
+				[icon] VARBINARY(MAX),
+				PRIMARY KEY([id])
+			);
+			GO
+
+			CREATE UNIQUE INDEX [INDEX_User_email] ON [User]([email]);
+			GO
+
+			CREATE TABLE [Job](
+				[id] INTEGER IDENTITY(1,1),
+				[name] NVARCHAR(30) NOT NULL,
+				PRIMARY KEY([id])
+			);
+			GO
+
+			CREATE TABLE [User_jobs](
+				[id] INTEGER IDENTITY(1,1) PRIMARY KEY,
+				[User_id] INTEGER,
+				[Job_id] INTEGER,
+				FOREIGN KEY([User_id]) REFERENCES [User]([id]),
+				FOREIGN KEY([Job_id]) REFERENCES [Job]([id])
+			);
+			GO
+			`),
+		},
+		{
+			name: "SQL Server with cycle",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Employee
+				  * @id
+				  * boss: *Employee.id?
+				`),
+				dialect: SQLServer,
+				opts:    []Option{WithNamingConvention(StandardNamingConvention)},
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE [Employee](
+				[id] INTEGER IDENTITY(1,1),
+				[boss] INTEGER,
+				CONSTRAINT [pk_Employee] PRIMARY KEY([id])
+			);
+			GO
+
+			ALTER TABLE [Employee] ADD CONSTRAINT [fk_Employee_boss_Employee] FOREIGN KEY([boss]) REFERENCES [Employee]([id]);
+			GO
+			`),
+		},
+		{
+			name: "Oracle",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * name: string
+				  * profile: text?
+				  * icon: blob?
+				  * group: *Group.id
+				* table: Group
+				  * @id
+				  * $code: varchar(10)
+				`),
+				dialect: Oracle,
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE "GROUP"(
+				ID NUMBER(19) GENERATED BY DEFAULT AS IDENTITY,
+				CODE VARCHAR2(10) NOT NULL,
+				PRIMARY KEY(ID)
+			);
+
+			CREATE UNIQUE INDEX INDEX_GROUP_CODE ON "GROUP"(CODE);
+
+			CREATE TABLE "USER"(
+				ID NUMBER(19) GENERATED BY DEFAULT AS IDENTITY,
+				NAME VARCHAR2(4000) NOT NULL,
+				PROFILE CLOB,
+				ICON BLOB,
+				"GROUP" NUMBER(19) NOT NULL,
+				PRIMARY KEY(ID),
+				FOREIGN KEY("GROUP") REFERENCES "GROUP"(ID)
+			);
+			`),
+		},
+		{
+			name: "Oracle 11g",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Organization
+				  * @id
+				  * name: string
+				`),
+				dialect: Oracle11g,
+				opts:    []Option{WithNamingConvention(StandardNamingConvention)},
+			},
+			want: TrimIndent(t, `
+			CREATE SEQUENCE ORGANIZATION_ID_SEQ;
+
+			CREATE TABLE ORGANIZATION(
+				ID NUMBER(19),
+				NAME VARCHAR2(4000) NOT NULL,
+				CONSTRAINT PK_ORGANIZATION PRIMARY KEY(ID)
+			);
+
+			CREATE OR REPLACE TRIGGER ORGANIZATION_ID_TRG
+			BEFORE INSERT ON ORGANIZATION
+			FOR EACH ROW
+			WHEN (new.ID IS NULL)
+			BEGIN
+				:new.ID := ORGANIZATION_ID_SEQ.NEXTVAL;
+			END;
+			/
+			`),
+		},
+		{
+			name: "DuckDB",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Event
+				  * @id
+				  * name: string
+				  * amount: hugeint
+				  * payload: blob?
+				  * tags: *Tag.name[]
+				* table: Tag
+				  * @name: string
+				`),
+				dialect: DuckDB,
+			},
+			want: TrimIndent(t, `
+			CREATE SEQUENCE Event_id_seq;
+
+			CREATE TABLE Event(
+				id BIGINT DEFAULT nextval('Event_id_seq'),
+				name VARCHAR NOT NULL,
+				amount HUGEINT NOT NULL,
+				payload BLOB,
+				PRIMARY KEY(id)
+			);
+
+			CREATE TABLE Tag(
+				name VARCHAR,
+				PRIMARY KEY(name)
+			);
+
+			CREATE SEQUENCE Event_tags_id_seq;
+
+			CREATE TABLE Event_tags(
+				id BIGINT DEFAULT nextval('Event_tags_id_seq') PRIMARY KEY,
+				Event_id BIGINT,
+				Tag_name VARCHAR,
+				FOREIGN KEY(Event_id) REFERENCES Event(id),
+				FOREIGN KEY(Tag_name) REFERENCES Tag(name)
+			);
+			`),
+		},
+		{
+			name: "DuckDB can't add foreign keys in cycles",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Employee
+				  * @id: integer
+				  * boss: *Employee.id?
+				`),
+				dialect: DuckDB,
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE Employee(
+				id INTEGER,
+				boss INTEGER,
+				PRIMARY KEY(id)
+			);
+
+			-- WARNING: Employee(boss) doesn't refer Employee because DuckDB can't add foreign keys in cycles
+
+			-- ALTER TABLE Employee ADD FOREIGN KEY(boss) REFERENCES Employee(id) (DuckDB can't add foreign keys to existing tables);
+			`),
+		},
+		{
+			name: "Spanner",
+			args: args{
+				src: TrimIndent(t, `
+				* master: Singers
+				  * @id
+				  * name: string
+				  * photo: blob?
+				* -tran: Albums
+				  * @id: *Singers.id
+				  * @albumId: int64
+				  * title: varchar(100)
+				* tran: Concerts
+				  * @concertId
+				  * singer: *Singers.id
+				`),
+				dialect: Spanner,
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE Singers(
+				id STRING(36) DEFAULT (GENERATE_UUID()),
+				name STRING(MAX) NOT NULL,
+				photo BYTES(MAX)
+			) PRIMARY KEY(id);
+
+			CREATE TABLE Albums(
+				id STRING(36),
+				albumId INT64,
+				title STRING(100) NOT NULL
+			) PRIMARY KEY(id, albumId),
+				INTERLEAVE IN PARENT Singers;
+
+			CREATE TABLE Concerts(
+				concertId STRING(36) DEFAULT (GENERATE_UUID()),
+				singer STRING(36) NOT NULL,
+				FOREIGN KEY(singer) REFERENCES Singers(id)
+			) PRIMARY KEY(concertId);
+			`),
+		},
+		{
+			name: "Spanner with bit-reversed sequence",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Singers
+				  * @id
+				  * songs: *Songs.id[]
+				* table: Songs
+				  * @id
+				`),
+				dialect: Spanner,
+				opts:    []Option{WithBitReversedSequence()},
+			},
+			want: TrimIndent(t, `
+			CREATE SEQUENCE Singers_id_seq OPTIONS (sequence_kind = 'bit_reversed_positive');
+
+			CREATE TABLE Singers(
+				id INT64 DEFAULT (GET_NEXT_SEQUENCE_VALUE(SEQUENCE Singers_id_seq))
+			) PRIMARY KEY(id);
+
+			CREATE SEQUENCE Songs_id_seq OPTIONS (sequence_kind = 'bit_reversed_positive');
+
+			CREATE TABLE Songs(
+				id INT64 DEFAULT (GET_NEXT_SEQUENCE_VALUE(SEQUENCE Songs_id_seq))
+			) PRIMARY KEY(id);
+
+			CREATE SEQUENCE Singers_songs_id_seq OPTIONS (sequence_kind = 'bit_reversed_positive');
+
+			CREATE TABLE Singers_songs(
+				id INT64 DEFAULT (GET_NEXT_SEQUENCE_VALUE(SEQUENCE Singers_songs_id_seq)),
+				Singers_id INT64,
+				Songs_id INT64,
+				FOREIGN KEY(Singers_id) REFERENCES Singers(id),
+				FOREIGN KEY(Songs_id) REFERENCES Songs(id)
+			) PRIMARY KEY(id);
+			`),
+		},
+		{
+			name: "ClickHouse",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * $email: string
+				  * nickname: string?
+				* table: Event
+				  * !engine: ReplacingMergeTree(version)
+				  * !partition_by: toYYYYMM(created_at)
+				  * @id: uuid
+				  * user: *User.id
+				  * referrer: *User.id?
+				  * created_at: timestamp
+				  * version: bigint
+				`),
+				dialect: ClickHouse,
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE User(
+				id UUID DEFAULT generateUUIDv4(),
+				email String NOT NULL,
+				nickname Nullable(String)
+			)
+			ENGINE = MergeTree()
+			ORDER BY (id);
+
+			-- CREATE UNIQUE INDEX INDEX_User_email ON User(email) (ClickHouse doesn't support unique indexes);
+
+			CREATE TABLE Event(
+				id UUID,
+				user UUID NOT NULL,
+				referrer Nullable(UUID),
+				created_at DateTime NOT NULL,
+				version Int64 NOT NULL
+			)
+			ENGINE = ReplacingMergeTree(version)
+			ORDER BY (id)
+			PARTITION BY toYYYYMM(created_at);
+
+			-- ALTER TABLE Event ADD FOREIGN KEY(user) REFERENCES User(id) (ClickHouse doesn't enforce foreign keys);
+
+			-- ALTER TABLE Event ADD FOREIGN KEY(referrer) REFERENCES User(id) (ClickHouse doesn't enforce foreign keys);
+			`),
+		},
+		{
+			name: "ClickHouse without primary key",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Log
+				  * !order_by: (level, message)
+				  * level: int16
+				  * message: text
+				* table: Metric
+				  * value: double
+				`),
+				dialect: ClickHouse,
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE Log(
+				level Int16 NOT NULL,
+				message String NOT NULL
+			)
+			ENGINE = MergeTree()
+			ORDER BY (level, message);
+
+			CREATE TABLE Metric(
+				value Float64 NOT NULL
+			)
+			ENGINE = MergeTree()
+			ORDER BY tuple();
+			`),
+		},
+		{
+			name: "BigQuery",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * $email: string
+				  * manager: *User.id?
+				* table: Event
+				  * !partition_by: DATE(created_at)
+				  * !cluster_by: user
+				  * @id: uuid
+				  * user: *User.id
+				  * created_at: timestamptz
+				  * payload: json?
+				`),
+				dialect: BigQuery,
+				opts:    []Option{WithDataset("analytics")},
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE analytics.User(
+				id STRING DEFAULT GENERATE_UUID(),
+				email STRING NOT NULL,
+				manager STRING,
+				PRIMARY KEY(id) NOT ENFORCED
+			);
+
+			-- CREATE UNIQUE INDEX INDEX_User_email ON analytics.User(email) (BigQuery doesn't support unique indexes);
+
+			CREATE TABLE analytics.Event(
+				id STRING,
+				user STRING NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				payload JSON,
+				PRIMARY KEY(id) NOT ENFORCED,
+				FOREIGN KEY(user) REFERENCES analytics.User(id) NOT ENFORCED
+			)
+			PARTITION BY DATE(created_at)
+			CLUSTER BY user;
+
+			ALTER TABLE analytics.User ADD FOREIGN KEY(manager) REFERENCES analytics.User(id) NOT ENFORCED;
+			`),
+		},
+		{
+			name: "BigQuery associative entity without dataset",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Event
+				  * @id
+				  * tags: *Tag.id[]
+				* table: Tag
+				  * @id
+				`),
+				dialect: BigQuery,
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE Event(
+				id STRING DEFAULT GENERATE_UUID(),
+				PRIMARY KEY(id) NOT ENFORCED
+			);
+
+			CREATE TABLE Tag(
+				id STRING DEFAULT GENERATE_UUID(),
+				PRIMARY KEY(id) NOT ENFORCED
+			);
+
+			CREATE TABLE Event_tags(
+				id STRING DEFAULT GENERATE_UUID(),
+				Event_id STRING,
+				Tag_id STRING,
+				PRIMARY KEY(id) NOT ENFORCED,
+				FOREIGN KEY(Event_id) REFERENCES Event(id) NOT ENFORCED,
+				FOREIGN KEY(Tag_id) REFERENCES Tag(id) NOT ENFORCED
+			);
+			`),
+		},
+		{
+			name: "SQLite keeps forward reference in table",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id: integer
+				  * job: *Job.id
+				* table: Job
+				  * @id: integer
+				`),
+				dialect: SQLite,
+				opts:    []Option{WithSourceOrder()},
+			},
+			want: TrimIndent(t, `
+			PRAGMA foreign_keys = ON;
+
+			CREATE TABLE User(
+				id INTEGER,
+				job INTEGER NOT NULL,
+				PRIMARY KEY(id),
+				FOREIGN KEY(job) REFERENCES Job(id)
+			);
+
+			CREATE TABLE Job(
+				id INTEGER,
+				PRIMARY KEY(id)
+			);
+			`),
+		},
+		{
+			name: "SQLite auto-increment key is inline primary key",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * tags: *Tag.id[]
+				* table: Tag
+				  * @id
+				`),
+				dialect: SQLite,
+				opts:    []Option{WithSourceOrder(), WithNamingConvention(StandardNamingConvention)},
+			},
+			want: TrimIndent(t, `
+			PRAGMA foreign_keys = ON;
+
+			CREATE TABLE User(
+				id INTEGER CONSTRAINT pk_User PRIMARY KEY AUTOINCREMENT
+			);
+
+			CREATE TABLE Tag(
+				id INTEGER CONSTRAINT pk_Tag PRIMARY KEY AUTOINCREMENT
+			);
+
+			CREATE TABLE User_tags(
+				id INTEGER CONSTRAINT pk_User_tags PRIMARY KEY AUTOINCREMENT,
+				User_id INTEGER,
+				Tag_id INTEGER,
+				CONSTRAINT fk_User_tags_User_id_User FOREIGN KEY(User_id) REFERENCES User(id),
+				CONSTRAINT fk_User_tags_Tag_id_Tag FOREIGN KEY(Tag_id) REFERENCES Tag(id)
+			);
+			`),
+		},
+		{
+			name: "SQLite STRICT and WITHOUT ROWID",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Tag
+				  * !strict
+				  * !without_rowid
+				  * @id
+				  * price: decimal(10,2)
+				  * at: timestamp?
+				* table: Pair
+				  * @left
+				  * @right: integer
+				`),
+				dialect: SQLite,
+				opts:    []Option{WithSourceOrder()},
+			},
+			want: TrimIndent(t, `
+			-- WARNING: Tag.id isn't auto-incremented because SQLite doesn't have AUTOINCREMENT on WITHOUT ROWID tables
+
+			CREATE TABLE Tag(
+				id INTEGER,
+				price ANY NOT NULL,
+				at TEXT,
+				PRIMARY KEY(id)
+			) STRICT, WITHOUT ROWID;
+
+			CREATE TABLE Pair(
+				left INTEGER,
+				right INTEGER,
+				PRIMARY KEY(left, right)
+			);
+			`),
+		},
+		{
+			name: "drop and create tables if not exists",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * $name: string
+				  * job: *Job.id?
+				  * tags: *Tag.id[]
+				* table: Job
+				  * @id
+				  * owner: *User.id
+				* table: Tag
+				  * @id
+				`),
+				opts: []Option{WithIfNotExists(), WithDropTables()},
+			},
+			want: TrimIndent(t, `
+			DROP TABLE IF EXISTS User_tags CASCADE;
+
+			DROP TABLE IF EXISTS Job CASCADE;
+
+			DROP TABLE IF EXISTS User CASCADE;
+
+			DROP TABLE IF EXISTS Tag CASCADE;
+
+			CREATE TABLE IF NOT EXISTS Tag(
+				id SERIAL,
+				PRIMARY KEY(id)
+			);
+
+			CREATE TABLE IF NOT EXISTS User(
+				id SERIAL,
+				name TEXT NOT NULL,
+				job INTEGER,
+				PRIMARY KEY(id)
+			);
+
+			CREATE UNIQUE INDEX IF NOT EXISTS INDEX_User_name ON User(name);
+
+			CREATE TABLE IF NOT EXISTS Job(
+				id SERIAL,
+				owner INTEGER NOT NULL,
+				PRIMARY KEY(id),
+				FOREIGN KEY(owner) REFERENCES User(id)
+			);
+
+			CREATE TABLE IF NOT EXISTS User_tags(
+				id SERIAL PRIMARY KEY,
+				User_id INTEGER,
+				Tag_id INTEGER,
+				FOREIGN KEY(User_id) REFERENCES User(id),
+				FOREIGN KEY(Tag_id) REFERENCES Tag(id)
+			);
+
+			ALTER TABLE User ADD FOREIGN KEY(job) REFERENCES Job(id);
+			`),
+		},
+		{
+			name: "drop tables on MySQL",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * $name: string
+				  * job: *Job.id?
+				* table: Job
+				  * @id
+				  * owner: *User.id
+				`),
+				dialect: MySQL,
+				opts:    []Option{WithIfNotExists(), WithDropTables()},
+			},
+			want: TrimIndent(t, `
+			SET FOREIGN_KEY_CHECKS = 0;
+
+			DROP TABLE IF EXISTS Job;
+
+			DROP TABLE IF EXISTS User;
+
+			SET FOREIGN_KEY_CHECKS = 1;
+
+			CREATE TABLE IF NOT EXISTS User(
+				id SERIAL,
+				name TEXT NOT NULL,
+				job BIGINT UNSIGNED,
+				PRIMARY KEY(id)
+			);
+
+			CREATE UNIQUE INDEX INDEX_User_name ON User(name);
+
+			CREATE TABLE IF NOT EXISTS Job(
+				id SERIAL,
+				owner BIGINT UNSIGNED NOT NULL,
+				PRIMARY KEY(id),
+				FOREIGN KEY(owner) REFERENCES User(id)
+			);
+
+			ALTER TABLE User ADD FOREIGN KEY(job) REFERENCES Job(id);
+			`),
+		},
+		{
+			name: "drop tables on SQL Server",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * job: *Job.id?
+				* table: Job
+				  * @id
+				  * owner: *User.id
+				`),
+				dialect: SQLServer,
+				opts:    []Option{WithDropTables()},
+			},
+			want: TrimIndent(t, `
+			IF EXISTS (SELECT * FROM sys.foreign_key_columns WHERE parent_object_id = OBJECT_ID(N'[User]') AND referenced_object_id = OBJECT_ID(N'[Job]') AND COL_NAME(parent_object_id, parent_column_id) = N'job')
+				ALTER TABLE [User] DROP CONSTRAINT [fk_User_job_Job];
+			GO
+
+			DROP TABLE IF EXISTS [Job];
+			GO
+
+			DROP TABLE IF EXISTS [User];
+			GO
+
+			CREATE TABLE [User](
+				[id] INTEGER IDENTITY(1,1),
+				[job] INTEGER,
+				PRIMARY KEY([id])
+			);
+			GO
+
+			CREATE TABLE [Job](
+				[id] INTEGER IDENTITY(1,1),
+				[owner] INTEGER NOT NULL,
+				PRIMARY KEY([id]),
+				FOREIGN KEY([owner]) REFERENCES [User]([id])
+			);
+			GO
+
+			ALTER TABLE [User] ADD CONSTRAINT [fk_User_job_Job] FOREIGN KEY([job]) REFERENCES [Job]([id]);
+			GO
+			`),
+		},
+		{
+			name: "add foreign keys in cycles if not exists",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * job: *Job.id?
+				* table: Job
+				  * @id
+				  * owner: *User.id
+				`),
+				opts: []Option{WithIfNotExists()},
+			},
+			want: TrimIndent(t, `
+			CREATE TABLE IF NOT EXISTS User(
+				id SERIAL,
+				job INTEGER,
+				PRIMARY KEY(id)
+			);
+
+			CREATE TABLE IF NOT EXISTS Job(
+				id SERIAL,
+				owner INTEGER NOT NULL,
+				PRIMARY KEY(id),
+				FOREIGN KEY(owner) REFERENCES User(id)
+			);
+
+			DO $$
+			BEGIN
+				IF NOT EXISTS (SELECT * FROM pg_constraint WHERE conrelid = 'User'::regclass AND conname = 'user_job_fkey') THEN
+					ALTER TABLE User ADD FOREIGN KEY(job) REFERENCES Job(id);
+				END IF;
+			END
+			$$;
+			`),
+		},
+		{
+			name: "add foreign keys in cycles if not exists on MySQL",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * $name: string
+				  * job: *Job.id?
+				* table: Job
+				  * @id
+				  * owner: *User.id
+				`),
+				dialect: MySQL,
+				opts:    []Option{WithIfNotExists()},
+			},
+			want: TrimIndent(t, `
+			-- WARNING: MySQL doesn't have CREATE INDEX IF NOT EXISTS, so the script can't run on the existing tables
+
+			CREATE TABLE IF NOT EXISTS User(
+				id SERIAL,
+				name TEXT NOT NULL,
+				job BIGINT UNSIGNED,
+				PRIMARY KEY(id)
+			);
+
+			CREATE UNIQUE INDEX INDEX_User_name ON User(name);
+
+			CREATE TABLE IF NOT EXISTS Job(
+				id SERIAL,
+				owner BIGINT UNSIGNED NOT NULL,
+				PRIMARY KEY(id),
+				FOREIGN KEY(owner) REFERENCES User(id)
+			);
+
+			SET @md2sql = IF(EXISTS(SELECT * FROM information_schema.key_column_usage WHERE table_schema = DATABASE() AND table_name = 'User' AND column_name = 'job' AND referenced_table_name = 'Job'), 'DO 0', 'ALTER TABLE User ADD FOREIGN KEY(job) REFERENCES Job(id)');
+			PREPARE md2sql FROM @md2sql;
+			EXECUTE md2sql;
+			DEALLOCATE PREPARE md2sql;
+			`),
+		},
+		{
+			name: "if not exists on SQL Server",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Tag
+				  * @id
+				  * $name: string
+				`),
+				dialect: SQLServer,
+				opts:    []Option{WithIfNotExists()},
+			},
+			want: TrimIndent(t, `
+			-- WARNING: SQLServer doesn't have CREATE TABLE IF NOT EXISTS and CREATE INDEX IF NOT EXISTS, so the script can't run on the existing tables
+
+			CREATE TABLE [Tag](
+				[id] INTEGER IDENTITY(1,1),
+				[name] NVARCHAR(450) NOT NULL,
+				PRIMARY KEY([id])
+			);
+			GO
+
+			CREATE UNIQUE INDEX [INDEX_Tag_name] ON [Tag]([name]);
+			GO
+			`),
+		},
+		{
+			name: "drop tables and sequences on DuckDB",
+			args: args{
+				src: TrimIndent(t, `
+				* table: User
+				  * @id
+				  * $name: string
+				  * tags: *Tag.id[]
+				* table: Tag
+				  * @id
+				`),
+				dialect: DuckDB,
+				opts:    []Option{WithIfNotExists(), WithDropTables(), WithSourceOrder()},
+			},
+			want: TrimIndent(t, `
+			DROP TABLE IF EXISTS User_tags;
+
+			DROP SEQUENCE IF EXISTS User_tags_id_seq;
+
+			DROP TABLE IF EXISTS Tag;
+
+			DROP SEQUENCE IF EXISTS Tag_id_seq;
+
+			DROP TABLE IF EXISTS User;
+
+			DROP SEQUENCE IF EXISTS User_id_seq;
+
+			CREATE SEQUENCE IF NOT EXISTS User_id_seq;
+
+			CREATE TABLE IF NOT EXISTS User(
+				id BIGINT DEFAULT nextval('User_id_seq'),
+				name VARCHAR NOT NULL,
+				PRIMARY KEY(id)
+			);
+
+			CREATE UNIQUE INDEX IF NOT EXISTS INDEX_User_name ON User(name);
+
+			CREATE SEQUENCE IF NOT EXISTS Tag_id_seq;
+
+			CREATE TABLE IF NOT EXISTS Tag(
+				id BIGINT DEFAULT nextval('Tag_id_seq'),
+				PRIMARY KEY(id)
+			);
+
+			CREATE SEQUENCE IF NOT EXISTS User_tags_id_seq;
+
+			CREATE TABLE IF NOT EXISTS User_tags(
+				id BIGINT DEFAULT nextval('User_tags_id_seq') PRIMARY KEY,
+				User_id BIGINT,
+				Tag_id BIGINT,
+				FOREIGN KEY(User_id) REFERENCES User(id),
+				FOREIGN KEY(Tag_id) REFERENCES Tag(id)
+			);
+			`),
+		},
+		{
+			name: "drop tables on Oracle",
+			args: args{
+				src: TrimIndent(t, `
+				* table: Tag
+				  * @id
+				  * $name: string
+				`),
+				dialect: Oracle,
+				opts:    []Option{WithIfNotExists(), WithDropTables()},
+			},
+			want: TrimIndent(t, `
+			DROP TABLE TAG CASCADE CONSTRAINTS;
+
+			CREATE TABLE TAG(
+				ID NUMBER(19) GENERATED BY DEFAULT AS IDENTITY,
+				NAME VARCHAR2(4000) NOT NULL,
+				PRIMARY KEY(ID)
+			);
+
+			CREATE UNIQUE INDEX INDEX_TAG_NAME ON TAG(NAME);
+			`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			tables, err := Parse(strings.NewReader(tt.args.src))
+			assert.NoError(t, err)
+			if err != nil {
+				return
+			}
+			DumpSQL(w, tables, tt.args.dialect, tt.args.opts...)
+			assert.Equal(t, tt.want, w.String())
+		})
+	}
+}
+
+func TestSQL_DropTablesInCycle(t *testing.T) {
+	tables, err := Parse(strings.NewReader(TrimIndent(t, `
+	* table: User
+	  * @id
+	  * job: *Job.id?
+	* table: Job
+	  * @id
+	  * owner: *User.id
+	`)))
+	assert.NoError(t, err)
+	// Spanner can't drop the foreign keys only if they exist
+	err = DumpSQL(&bytes.Buffer{}, tables, Spanner, WithDropTables())
+	assert.Error(t, err)
 }
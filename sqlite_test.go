@@ -0,0 +1,207 @@
+package md2sql
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+// openSQLite opens a new in-memory SQLite database.
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	assert.NoError(t, err)
+	// each connection has its own in-memory database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		db.Close()
+	})
+	return db
+}
+
+// execSQLite executes the script on the SQLite database.
+func execSQLite(t *testing.T, db *sql.DB, script string) {
+	t.Helper()
+	for _, stmt := range strings.Split(script, ";\n") {
+		if strings.TrimSpace(stmt) == "" {
+			continue
+		}
+		_, err := db.Exec(stmt)
+		assert.NoError(t, err, stmt)
+	}
+}
+
+func TestSQLite_Execute(t *testing.T) {
+	sources := map[string]string{
+		"foreign key cycle": TrimIndent(t, `
+		* table: User
+		  * @id
+		  * job: *Job.id?
+		* table: Job
+		  * @id
+		  * owner: *User.id
+		`),
+		"self reference": TrimIndent(t, `
+		* table: Employee
+		  * @id
+		  * boss: *Employee.id?
+		`),
+		"composite key": TrimIndent(t, `
+		* table: Pair
+		  * @left
+		  * @right: integer
+		  * tags: *Tag.id[]
+		* table: Tag
+		  * @id
+		* table: Child
+		  * @id
+		  * left: *Pair.left
+		  * right: *Pair.right
+		`),
+		"strict and without rowid": TrimIndent(t, `
+		* table: Tag
+		  * !strict
+		  * !without_rowid
+		  * @id
+		  * price: decimal(10,2)
+		  * score: float
+		  * at: timestamp?
+		* table: Event
+		  * !strict
+		  * @id
+		  * memo: text?
+		  * data: binary
+		  * tags: *Tag.id[]
+		* table: Log
+		  * !strict: false
+		  * @id
+		  * tag: *Tag.id
+		`),
+		"various types": TrimIndent(t, `
+		* table: Item
+		  * @code: varchar(10)
+		  * $name: string
+		  * price: decimal(10,2)
+		  * count: smallint
+		  * total: bigint
+		  * rate: double
+		  * active: bool
+		  * day: date
+		  * at: timestamptz
+		  * ref: uuid
+		  * meta: json
+		  * body: binary
+		`),
+	}
+	matches, err := filepath.Glob("testdata/fixtures/*.md")
+	assert.NoError(t, err)
+	for _, match := range matches {
+		src, err := os.ReadFile(match)
+		assert.NoError(t, err)
+		sources[filepath.Base(match)] = string(src)
+	}
+	for name, src := range sources {
+		tables, err := Parse(strings.NewReader(src))
+		assert.NoError(t, err)
+		if err != nil {
+			continue
+		}
+		namings := map[string]NamingConvention{
+			"default":  DefaultNamingConvention,
+			"standard": StandardNamingConvention,
+		}
+		for namingName, naming := range namings {
+			t.Run(name+"/"+namingName, func(t *testing.T) {
+				var out bytes.Buffer
+				err := DumpSQL(&out, tables, SQLite, WithNamingConvention(naming))
+				assert.NoError(t, err)
+				execSQLite(t, openSQLite(t), out.String())
+			})
+		}
+	}
+}
+
+func TestSQLite_ExecuteDropTables(t *testing.T) {
+	tables := parseForTest(t, TrimIndent(t, `
+	* table: User
+	  * @id
+	  * $name: string
+	  * job: *Job.id?
+	  * tags: *Tag.id[]
+	* table: Job
+	  * @id
+	  * owner: *User.id?
+	* table: Tag
+	  * @id
+	`))
+	var out bytes.Buffer
+	err := DumpSQL(&out, tables, SQLite, WithIfNotExists(), WithDropTables())
+	assert.NoError(t, err)
+	db := openSQLite(t)
+	// the script can be run repeatedly
+	for i := 0; i < 2; i++ {
+		execSQLite(t, db, out.String())
+		_, err = db.Exec("INSERT INTO User(name) VALUES ('shibukawa')")
+		assert.NoError(t, err)
+	}
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT count(*) FROM User").Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestSQLite_ExecuteMigration(t *testing.T) {
+	from := TrimIndent(t, `
+	* table: User
+	  * @id
+	  * name: string
+	  * $email: string
+	  * job: *Job.id?
+	  * tags: *Tag.id[]
+	* table: Job
+	  * @id
+	  * name: string
+	* table: Tag
+	  * @id
+	`)
+	to := TrimIndent(t, `
+	* table: User
+	  * @id
+	  * name: string
+	  * email: string
+	  * $nickname: string?
+	  * job: *Job.id?
+	  * team: *Team.id?
+	* table: Job
+	  * @id
+	* table: Team
+	  * @id
+	  * name: string
+	  * members: *User.id[]
+	* table: Tag
+	  * @id
+	  * label: text?
+	`)
+	db := openSQLite(t)
+	var out bytes.Buffer
+	err := DumpSQL(&out, parseForTest(t, from), SQLite)
+	assert.NoError(t, err)
+	execSQLite(t, db, out.String())
+
+	out.Reset()
+	err = DumpMigration(&out, parseForTest(t, from), parseForTest(t, to), SQLite)
+	assert.NoError(t, err)
+	execSQLite(t, db, out.String())
+
+	_, err = db.Exec("INSERT INTO Team(name) VALUES ('core')")
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO User(name, email, nickname, team) VALUES ('shibukawa', 'yoshiki@example.com', 'shibu', 1)")
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO Team_members(Team_id, User_id) VALUES (1, 1)")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,270 @@
+package md2sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// aliases of portable type names
+var typeAliases = map[string]string{
+	"int16":    "smallint",
+	"int32":    "integer",
+	"int64":    "bigint",
+	"float32":  "float",
+	"real":     "float",
+	"float64":  "double",
+	"numeric":  "decimal",
+	"boolean":  "bool",
+	"datetime": "timestamp",
+	"blob":     "binary",
+	"lob":      "binary",
+	"bytes":    "binary",
+}
+
+// splitType splits "decimal(10, 2)" into "decimal" and "10,2".
+func splitType(t string) (name, args string) {
+	name, args, ok := strings.Cut(t, "(")
+	if !ok {
+		return strings.TrimSpace(t), ""
+	}
+	args = strings.TrimSuffix(strings.TrimSpace(args), ")")
+	return strings.TrimSpace(name), strings.ReplaceAll(args, " ", "")
+}
+
+func portableTypeName(name string) string {
+	name = strings.ToLower(name)
+	if alias, ok := typeAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+func expandType(sqlType, args string) string {
+	if args == "" {
+		return strings.ReplaceAll(sqlType, "({args})", "")
+	}
+	return strings.ReplaceAll(sqlType, "{args}", args)
+}
+
+// TypeWarning reports a column type that is unknown for the dialect.
+// The type is passed to SQL as is.
+type TypeWarning struct {
+	Table   string
+	Column  string
+	Type    string
+	Dialect Dialect
+}
+
+func (w TypeWarning) String() string {
+	return fmt.Sprintf("%s.%s: type '%s' is unknown for %s", w.Table, w.Column, w.Type, w.Dialect)
+}
+
+// CheckTypes returns warnings of column types that are unknown for the dialect.
+func CheckTypes(tables []*Table, d Dialect, opts ...Option) []TypeWarning {
+	md := newOption(opts).dialect(d)
+	var result []TypeWarning
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if c.Type == "" || c.LinkTable != "" {
+				continue
+			}
+			if !md.IsKnownType(c.Type) {
+				result = append(result, TypeWarning{
+					Table:   t.Name,
+					Column:  c.Name,
+					Type:    c.Type,
+					Dialect: md.Dialect,
+				})
+			}
+		}
+	}
+	return result
+}
+
+// TypeMapping overrides the built-in type mapping of each dialect.
+// The key of the inner map is a type name in the Markdown and the value is a SQL type.
+// "{args}" in the SQL type is replaced with the arguments of the source type.
+//
+// The special key "autoincrement" overrides the auto-increment primary key like "BIGINT UNSIGNED AUTO_INCREMENT".
+// It is split into the type and the modifier at the first keyword that fills the key, and the columns that refer
+// the key use the type. Pseudo types like "BIGSERIAL" use the type of the dialect's strategy of the same name.
+// Identity strategies (WithIdentity and "!identity") take precedence over it.
+type TypeMapping map[Dialect]map[string]string
+
+const autoIncrementType = "autoincrement"
+
+// parseAutoIncrement splits the key definition like "BIGINT GENERATED BY DEFAULT AS IDENTITY" into the type and the modifier.
+// The empty definition is the default of the dialect.
+func parseAutoIncrement(d Dialect, sqlType string) AutoIncrement {
+	sqlType = strings.TrimSpace(sqlType)
+	words := strings.Fields(sqlType)
+	if len(words) == 0 {
+		a, _ := d.Identity("")
+		return a
+	}
+	for _, strategy := range []string{"", strings.ToLower(sqlType)} {
+		if a, ok := d.Identity(strategy); ok && strings.EqualFold(a.Pseudo, sqlType) {
+			return a
+		}
+	}
+	for i, word := range words[1:] {
+		keyword, _, _ := strings.Cut(strings.ToUpper(word), "(")
+		if keyword == "GENERATED" || keyword == "DEFAULT" || contains(autoIncrementMarkers, keyword) {
+			return AutoIncrement{Type: strings.Join(words[:i+1], " "), Modifier: strings.Join(words[i+1:], " ")}
+		}
+	}
+	return AutoIncrement{Type: strings.Join(words, " ")}
+}
+
+// mappedDialect is a Dialect with user-defined type mapping and options.
+type mappedDialect struct {
+	Dialect
+	types               map[string]string
+	bitReversedSequence bool
+	// identity is the default identity strategy of the project
+	identity string
+	// dataset qualifies table names (BigQuery)
+	dataset string
+}
+
+// Identity returns the auto-increment definition of the strategy. The default strategy can be overridden by the type mapping.
+func (d mappedDialect) Identity(strategy string) (AutoIncrement, bool) {
+	if sqlType, ok := d.types[autoIncrementType]; ok && strategy == "" {
+		return parseAutoIncrement(d.Dialect, sqlType), true
+	}
+	return d.Dialect.Identity(strategy)
+}
+
+func (d mappedDialect) PrimaryKeySQLType(t string, autoIncrement bool) string {
+	if t == "" {
+		if !autoIncrement {
+			return d.PrimaryKeyBaseType("")
+		}
+		if _, ok := d.types[autoIncrementType]; ok {
+			a, _ := d.Identity("")
+			return a.KeyType()
+		}
+		if d.bitReversedSequence {
+			return "INT64"
+		}
+		return d.Dialect.PrimaryKeySQLType("", true)
+	}
+	if sqlType, ok := d.lookup(t); ok {
+		return sqlType
+	}
+	return d.Dialect.PrimaryKeySQLType(t, autoIncrement)
+}
+
+func (d mappedDialect) PrimaryKeyBaseType(t string) string {
+	if t == "" {
+		if _, ok := d.types[autoIncrementType]; ok {
+			a, _ := d.Identity("")
+			return a.Type
+		}
+		if d.bitReversedSequence {
+			return "INT64"
+		}
+		return d.Dialect.PrimaryKeyBaseType("")
+	}
+	if sqlType, ok := d.lookup(t); ok {
+		return sqlType
+	}
+	return d.Dialect.PrimaryKeyBaseType(t)
+}
+
+// columnType returns the SQL type of the column without auto-increment modifiers.
+// Keys and unique columns have the key types of the dialect like the bounded NVARCHAR of SQL Server.
+func (d mappedDialect) columnType(c *Column) string {
+	if c.PrimaryKey || c.LinkTable != "" {
+		return d.baseType(c)
+	}
+	if c.Index && c.Type != "" {
+		return d.PrimaryKeyBaseType(c.Type)
+	}
+	return d.TypeConversion(c.Type)
+}
+
+// keyType returns the type of the primary key column.
+func (d mappedDialect) keyType(c *Column) string {
+	if c.Type == "" && !c.AutoIncrement {
+		return d.baseType(c)
+	}
+	if c.Type == "" {
+		if a, ok := d.identityOf(c); ok {
+			return a.KeyType()
+		}
+	}
+	return d.PrimaryKeySQLType(c.Type, c.AutoIncrement)
+}
+
+// baseType returns the type of the key column without auto-increment that is shared by the columns that refer it.
+func (d mappedDialect) baseType(c *Column) string {
+	if c.Type == "" {
+		if a, ok := d.identityOf(c); ok {
+			return a.Type
+		}
+	}
+	return d.PrimaryKeyBaseType(c.Type)
+}
+
+// identityOf returns the auto-increment definition of the column's identity strategy or the project default.
+// The strategy can have the function that generates keys like "uuidv7:uuid_generate_v7()".
+func (d mappedDialect) identityOf(c *Column) (AutoIncrement, bool) {
+	identity := c.Identity
+	if identity == "" {
+		identity = d.identity
+	}
+	if identity == "" {
+		return AutoIncrement{}, false
+	}
+	strategy, function, _ := strings.Cut(identity, ":")
+	a, ok := d.Identity(strings.TrimSpace(strategy))
+	if ok && function != "" {
+		a.Modifier = "DEFAULT " + strings.TrimSpace(function)
+		a.Pseudo = ""
+	}
+	return a, ok
+}
+
+// inlineKey returns the auto-increment key column definition with PRIMARY KEY like "INTEGER PRIMARY KEY AUTOINCREMENT".
+func (d mappedDialect) inlineKey(c *Column, constraint string) string {
+	a, ok := d.identityOf(c)
+	if !ok {
+		a, _ = d.Identity("")
+	}
+	result := a.Type + " " + constraint + "PRIMARY KEY"
+	if a.Modifier != "" {
+		result += " " + a.Modifier
+	}
+	return result
+}
+
+func (d mappedDialect) Capabilities() Capabilities {
+	c := d.Dialect.Capabilities()
+	c.SequenceDefault = c.SequenceDefault || d.bitReversedSequence
+	return c
+}
+
+func (d mappedDialect) TypeConversion(t string) string {
+	if sqlType, ok := d.lookup(t); ok {
+		return sqlType
+	}
+	return d.Dialect.TypeConversion(t)
+}
+
+func (d mappedDialect) IsKnownType(t string) bool {
+	if _, ok := d.lookup(t); ok {
+		return true
+	}
+	return d.Dialect.IsKnownType(t)
+}
+
+func (d mappedDialect) lookup(t string) (string, bool) {
+	name, args := splitType(t)
+	for _, key := range []string{strings.ToLower(name), portableTypeName(name)} {
+		if sqlType, ok := d.types[key]; ok {
+			return expandType(sqlType, args), true
+		}
+	}
+	return "", false
+}
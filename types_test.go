@@ -0,0 +1,117 @@
+package md2sql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeConversion(t *testing.T) {
+	type args struct {
+		t string
+		d Dialect
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "string",
+			args: args{t: "string", d: PostgreSQL},
+			want: "TEXT",
+		},
+		{
+			name: "alias",
+			args: args{t: "boolean", d: PostgreSQL},
+			want: "BOOLEAN",
+		},
+		{
+			name: "bool for SQLite",
+			args: args{t: "bool", d: SQLite},
+			want: "INTEGER",
+		},
+		{
+			name: "decimal with precision and scale",
+			args: args{t: "decimal(10, 2)", d: PostgreSQL},
+			want: "NUMERIC(10,2)",
+		},
+		{
+			name: "decimal for MySQL",
+			args: args{t: "numeric(10,2)", d: MySQL},
+			want: "DECIMAL(10,2)",
+		},
+		{
+			name: "varchar",
+			args: args{t: "varchar(30)", d: MySQL},
+			want: "VARCHAR(30)",
+		},
+		{
+			name: "varchar for SQLite drops length",
+			args: args{t: "varchar(30)", d: SQLite},
+			want: "TEXT",
+		},
+		{
+			name: "timestamp with time zone",
+			args: args{t: "timestamptz", d: PostgreSQL},
+			want: "TIMESTAMP WITH TIME ZONE",
+		},
+		{
+			name: "timestamp with time zone and precision",
+			args: args{t: "timestamptz(3)", d: PostgreSQL},
+			want: "TIMESTAMP(3) WITH TIME ZONE",
+		},
+		{
+			name: "datetime for MySQL",
+			args: args{t: "datetime", d: MySQL},
+			want: "DATETIME",
+		},
+		{
+			name: "uuid for MySQL",
+			args: args{t: "uuid", d: MySQL},
+			want: "CHAR(36)",
+		},
+		{
+			name: "blob for PostgreSQL",
+			args: args{t: "blob", d: PostgreSQL},
+			want: "BYTEA",
+		},
+		{
+			name: "unknown type",
+			args: args{t: "geometry", d: PostgreSQL},
+			want: "GEOMETRY",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.args.d.TypeConversion(tt.args.t))
+		})
+	}
+}
+
+func TestCheckTypes(t *testing.T) {
+	tables, err := Parse(strings.NewReader(TrimIndent(t, `
+	* table: User
+	  * @id
+	  * name: varchar(20)
+	  * birthday: date
+	  * profile: jsonb
+	  * location: geometry
+	  * job: *Job.id
+	* table: Job
+	  * @id
+	`)))
+	assert.NoError(t, err)
+
+	warnings := CheckTypes(tables, PostgreSQL)
+	assert.Equal(t, []TypeWarning{
+		{Table: "User", Column: "location", Type: "geometry", Dialect: PostgreSQL},
+	}, warnings)
+	assert.Equal(t, "User.location: type 'geometry' is unknown for PostgreSQL", warnings[0].String())
+
+	// jsonb is PostgreSQL only
+	warnings = CheckTypes(tables, MySQL)
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, "profile", warnings[0].Column)
+}